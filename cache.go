@@ -4,19 +4,28 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
 // CacheEntry represents a single entry in the cache.
+// Expires and LastModified are only set by providers that honour HTTP caching headers.
+// Permanent entries hold data that does not change, such as resolved grid points, and are never cleaned.
 type CacheEntry struct {
-	Timestamp time.Time          `json:"timestamp"`
-	Weather   *WeatherAPIResponse `json:"weather"`
+	Timestamp    time.Time       `json:"timestamp"`
+	Weather      *Weather        `json:"weather,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+	Permanent    bool            `json:"permanent,omitempty"`
+	Expires      time.Time       `json:"expires,omitzero"`
+	LastModified string          `json:"lastModified,omitempty"`
 }
 
 // Cache represents the cache of weather data.
+// It is safe for concurrent use, as the locations of a comparison are fetched concurrently.
 type Cache struct {
 	Entries  map[string]CacheEntry `json:"entries"`
 	filePath string
+	mu       sync.Mutex
 }
 
 // NewCache creates a new Cache instance and loads the cache from disk.
@@ -32,17 +41,38 @@ func NewCache(configPath string) (*Cache, error) {
 	return cache, nil
 }
 
+// newMemoryCache creates a Cache that is never written to disk.
+func newMemoryCache() *Cache {
+	return &Cache{Entries: make(map[string]CacheEntry)}
+}
+
 // load reads the cache file from disk and unmarshals it into the Cache struct.
+// Entries that do not decode, such as those written by an older version in another format,
+// are skipped; they are fetched again and overwritten on the next save.
 func (c *Cache) load() error {
 	data, err := os.ReadFile(c.filePath)
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &c.Entries)
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for key, value := range raw {
+		var entry CacheEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			continue
+		}
+		c.Entries[key] = entry
+	}
+	return nil
 }
 
-// save writes the cache to disk as a JSON file.
+// save writes the cache to disk as a JSON file. A memory cache is not saved.
 func (c *Cache) save() error {
+	if c.filePath == "" {
+		return nil
+	}
 	data, err := json.MarshalIndent(c.Entries, "", "  ")
 	if err != nil {
 		return err
@@ -52,31 +82,87 @@ func (c *Cache) save() error {
 
 // Get retrieves a cache entry for a given location.
 func (c *Cache) Get(location string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	entry, found := c.Entries[location]
 	return &entry, found
 }
 
 // Set adds or updates a cache entry and saves the cache to disk.
-func (c *Cache) Set(location string, weather *WeatherAPIResponse) error {
-	c.Clean(time.Hour)
-	c.Entries[location] = CacheEntry{
-		Timestamp: time.Now(),
-		Weather:   weather,
+func (c *Cache) Set(location string, weather *Weather) error {
+	return c.SetEntry(location, CacheEntry{Weather: weather})
+}
+
+// SetEntry adds or updates a full cache entry, including its HTTP caching metadata,
+// and saves the cache to disk. A zero Timestamp is set to the current time.
+func (c *Cache) SetEntry(location string, entry CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clean(time.Hour)
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
 	}
+	c.Entries[location] = entry
 	return c.save()
 }
 
+// SetPermanent stores arbitrary JSON-encodable data under a key that is never cleaned.
+func (c *Cache) SetPermanent(key string, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.SetEntry(key, CacheEntry{Data: raw, Permanent: true})
+}
+
+// GetPermanent decodes the data stored with SetPermanent into v.
+// It returns false if the key is not found or the data cannot be decoded.
+func (c *Cache) GetPermanent(key string, v interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, found := c.Entries[key]
+	if !found || entry.Data == nil {
+		return false
+	}
+	return json.Unmarshal(entry.Data, v) == nil
+}
+
 // IsStale checks if the cache entry is older than the given duration.
+// Entries carrying an Expires time from the server are stale once that time has passed instead.
+// Permanent entries are never stale.
 func (e *CacheEntry) IsStale(duration time.Duration) bool {
+	if e.Permanent {
+		return false
+	}
+	if !e.Expires.IsZero() {
+		return time.Now().After(e.Expires)
+	}
+	return e.IsOlderThan(duration)
+}
+
+// IsOlderThan checks if the cache entry was stored more than the given duration ago.
+func (e *CacheEntry) IsOlderThan(duration time.Duration) bool {
 	return time.Since(e.Timestamp) > duration
 }
 
-// Clean removes stale entries from the cache and saves the cache to disk.
+// Clean removes old entries from the cache and saves the cache to disk.
 func (c *Cache) Clean(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clean(duration)
+	c.save()
+}
+
+// clean removes old entries from the cache, with the cache locked. Entries whose Expires time
+// from the server has not passed yet are kept, whatever their age.
+func (c *Cache) clean(duration time.Duration) {
+	now := time.Now()
 	for location, entry := range c.Entries {
-		if entry.IsStale(duration) {
+		if entry.Permanent || entry.Expires.After(now) {
+			continue
+		}
+		if entry.IsOlderThan(duration) {
 			delete(c.Entries, location)
 		}
 	}
-	c.save()
 }
\ No newline at end of file
@@ -38,6 +38,26 @@ func TestCache(t *testing.T) {
 		assert.Contains(t, err.Error(), "invalid character")
 	})
 
+	t.Run("NewCache skips entries in an old format", func(t *testing.T) {
+		os.Remove(cachePath) // Ensure clean slate
+		// The previous version stored the raw weatherapi.com response, with an object as condition
+		old := `{
+  "Brussels": {"timestamp": "2025-01-12T09:00:00Z", "weather": {"current": {"is_day": 1, "condition": {"text": "Sunny", "code": 1000}}}},
+  "Gent": {"timestamp": "2025-01-12T09:00:00Z", "weather": {"location": {"name": "Gent"}}}
+}`
+		err = os.WriteFile(cachePath, []byte(old), 0644)
+		assert.NoError(t, err)
+
+		cache, err := NewCache(cachePath)
+		assert.NoError(t, err)
+		assert.NotNil(t, cache)
+		_, found := cache.Get("Brussels")
+		assert.False(t, found)
+		entry, found := cache.Get("Gent")
+		assert.True(t, found)
+		assert.Equal(t, "Gent", entry.Weather.Location.Name)
+	})
+
 	t.Run("NewCache with unreadable file", func(t *testing.T) {
 		os.Remove(cachePath) // Ensure clean slate
 		// Create an empty file to set permissions on
@@ -63,8 +83,8 @@ func TestCache(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Create a mock weather response
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Test Location",
 			},
 		}
@@ -86,8 +106,8 @@ func TestCache(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Create a mock weather response
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Test Location",
 			},
 		}
@@ -106,6 +126,13 @@ func TestCache(t *testing.T) {
 		// Manipulate the timestamp to make it stale
 		entry.Timestamp = time.Now().Add(-2 * time.Hour)
 		assert.True(t, entry.IsStale(time.Hour))
+		// An Expires time from the server takes precedence over the age of the entry
+		entry.Expires = time.Now().Add(time.Hour)
+		assert.False(t, entry.IsStale(time.Hour))
+		entry.Timestamp = time.Now()
+		entry.Expires = time.Now().Add(-time.Minute)
+		assert.True(t, entry.IsStale(time.Hour))
+		assert.False(t, entry.IsOlderThan(time.Hour))
 	})
 
 	t.Run("Load and Save", func(t *testing.T) {
@@ -114,8 +141,8 @@ func TestCache(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Create a mock weather response
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Test Location",
 			},
 		}
@@ -140,8 +167,8 @@ func TestCache(t *testing.T) {
 		cache, err := NewCache(cachePath)
 		assert.NoError(t, err)
 
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Stale Location",
 			},
 		}
@@ -151,8 +178,8 @@ func TestCache(t *testing.T) {
 			Weather:   mockWeather,
 		}
 
-		mockWeather2 := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather2 := &Weather{
+			Location: WeatherLocation{
 				Name: "Fresh Location",
 			},
 		}
@@ -196,6 +223,41 @@ func TestCache(t *testing.T) {
 		noStaleCache.Entries["Fresh2"] = CacheEntry{Timestamp: time.Now().Add(-45 * time.Minute), Weather: mockWeather}
 		noStaleCache.Clean(time.Hour)
 		assert.Len(t, noStaleCache.Entries, 2)
+
+		// Old entries the server said are still valid are kept, also when another entry is set
+		os.Remove(cachePath)
+		expiresCache, err := NewCache(cachePath)
+		assert.NoError(t, err)
+		expiresCache.Entries["Valid"] = CacheEntry{Timestamp: time.Now().Add(-2 * time.Hour), Expires: time.Now().Add(time.Hour),
+			LastModified: "Sun, 12 Jan 2025 18:00:00 GMT", Weather: mockWeather}
+		expiresCache.Entries["Expired"] = CacheEntry{Timestamp: time.Now().Add(-2 * time.Hour), Expires: time.Now().Add(-time.Hour), Weather: mockWeather}
+		assert.NoError(t, expiresCache.Set("Other Location", mockWeather2))
+		entry, found := expiresCache.Get("Valid")
+		assert.True(t, found)
+		assert.Equal(t, "Sun, 12 Jan 2025 18:00:00 GMT", entry.LastModified)
+		_, found = expiresCache.Get("Expired")
+		assert.False(t, found)
+	})
+
+	t.Run("Permanent entries", func(t *testing.T) {
+		os.Remove(cachePath) // Ensure clean slate
+		cache, err := NewCache(cachePath)
+		assert.NoError(t, err)
+
+		err = cache.SetPermanent("Permanent Location", map[string]string{"grid": "TOP/31,80"})
+		assert.NoError(t, err)
+
+		// Make the entry old; permanent entries are neither stale nor cleaned
+		entry := cache.Entries["Permanent Location"]
+		entry.Timestamp = time.Now().Add(-48 * time.Hour)
+		cache.Entries["Permanent Location"] = entry
+		assert.False(t, entry.IsStale(time.Hour))
+		cache.Clean(time.Hour)
+
+		var data map[string]string
+		assert.True(t, cache.GetPermanent("Permanent Location", &data))
+		assert.Equal(t, "TOP/31,80", data["grid"])
+		assert.False(t, cache.GetPermanent("Missing Location", &data))
 	})
 
 	t.Run("Set error handling", func(t *testing.T) {
@@ -203,8 +265,8 @@ func TestCache(t *testing.T) {
 		cache, err := NewCache(cachePath)
 		assert.NoError(t, err)
 
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Initial Location",
 			},
 		}
@@ -212,8 +274,8 @@ func TestCache(t *testing.T) {
 		err = cache.Set("Initial Location", mockWeather)
 		assert.NoError(t, err)
 
-		mockWeatherError := &WeatherAPIResponse{
-			Location: Location{
+		mockWeatherError := &Weather{
+			Location: WeatherLocation{
 				Name: "Error Location",
 			},
 		}
@@ -236,8 +298,8 @@ func TestCache(t *testing.T) {
 		cache, err := NewCache(cachePath)
 		assert.NoError(t, err)
 
-		mockWeather := &WeatherAPIResponse{
-			Location: Location{
+		mockWeather := &Weather{
+			Location: WeatherLocation{
 				Name: "Save Error Location",
 			},
 		}
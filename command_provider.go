@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultCommandTimeout is how long the external command may run when no timeout is configured.
+const defaultCommandTimeout = 10 * time.Second
+
+// commandWaitDelay is how long the output of a timed out command is still waited for once it is killed.
+// Child processes it started may keep its output open, so the output is closed after this delay.
+const commandWaitDelay = 500 * time.Millisecond
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "command",
+		Description: "External command printing the weather as JSON (see command)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &commandProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily},
+	})
+}
+
+// commandProvider is the implementation of WeatherProvider that runs a configured command
+// and parses its standard output as JSON in the provider-neutral Weather schema.
+// The requested location is passed to the command in the WAYTHER_LOCATION environment variable.
+type commandProvider struct {
+	cache *Cache
+}
+
+// GetWeather runs the command from the config and decodes its output.
+// The command is killed if it runs longer than the configured timeout, and its standard
+// error is included in the returned error when it fails.
+func (p *commandProvider) GetWeather(c *Config) (*Weather, error) {
+	if len(c.Command) == 0 {
+		return nil, fmt.Errorf("command is not set in the config")
+	}
+
+	key := cacheKey("command", c.Location)
+
+	// Check cache first
+	if !c.NoCache {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
+			return entry.Weather, nil
+		}
+	}
+
+	timeout := defaultCommandTimeout
+	if c.CommandTimeout > 0 {
+		timeout = time.Duration(c.CommandTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	cmd.Env = append(os.Environ(), "WAYTHER_LOCATION="+c.Location)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = commandWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("command timed out after %s", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("command failed: %w: %s", err, message)
+		}
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+
+	weather := &Weather{}
+	if err := json.Unmarshal(stdout.Bytes(), weather); err != nil {
+		return nil, fmt.Errorf("failed to decode command output: %w", err)
+	}
+	weather.fillDefaults(c.Location)
+
+	// Save to cache
+	if err := p.cache.Set(key, weather); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *commandProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// fillDefaults completes the fields that a hand-written source may leave out:
+// the location names, the source attribution, and emojis derived from the condition text.
+func (w *Weather) fillDefaults(location string) {
+	if w.Location.Name == "" {
+		w.Location.Name = location
+	}
+	if w.Current.Location == "" {
+		w.Current.Location = w.Location.Name
+	}
+	if w.Current.Country == "" {
+		w.Current.Country = w.Location.Country
+	}
+	if w.Source.Provider == "" {
+		w.Source.Provider = "command"
+	}
+	if w.Current.Emoji == "" && w.Current.Condition != "" {
+		w.Current.Emoji = getEmojiForConditionText(w.Current.Condition)
+	}
+	for i := range w.HourlyForecast {
+		if w.HourlyForecast[i].Emoji == "" && w.HourlyForecast[i].Condition != "" {
+			w.HourlyForecast[i].Emoji = getEmojiForConditionText(w.HourlyForecast[i].Condition)
+		}
+	}
+	for i := range w.DailyForecast {
+		if w.DailyForecast[i].Emoji == "" && w.DailyForecast[i].Condition != "" {
+			w.DailyForecast[i].Emoji = getEmojiForConditionText(w.DailyForecast[i].Condition)
+		}
+	}
+}
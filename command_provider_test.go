@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandProvider_GetWeather(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "cache.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider := &commandProvider{cache: cache}
+
+	t.Run("Parses the command output", func(t *testing.T) {
+		config := &Config{
+			Location: "Backyard",
+			NoCache:  true,
+			Command: []string{"sh", "-c", `printf '{"location": {"name": "%s"}, "current": {"condition": "Light rain", "temp_c": 12.5},
+				"hourly": [{"time_epoch": 1700000000, "condition": "Sunny", "temp_c": 14}]}' "$WAYTHER_LOCATION"`},
+		}
+
+		weather, err := provider.GetWeather(config)
+		if err != nil {
+			t.Fatalf("GetWeather returned an error: %v", err)
+		}
+
+		assert.Equal(t, "Backyard", weather.Location.Name)
+		assert.Equal(t, "Backyard", weather.Current.Location)
+		assert.Equal(t, 12.5, weather.Current.TempC)
+		assert.Equal(t, getEmojiForConditionText("Light rain"), weather.Current.Emoji)
+		assert.Equal(t, "command", weather.Source.Provider)
+		if assert.Len(t, weather.HourlyForecast, 1) {
+			assert.Equal(t, getEmojiForConditionText("Sunny"), weather.HourlyForecast[0].Emoji)
+		}
+	})
+
+	t.Run("Uses the cache", func(t *testing.T) {
+		config := &Config{Location: "Cached", Command: []string{"sh", "-c", `echo '{"current": {"temp_c": 1}}'`}}
+		if _, err := provider.GetWeather(config); err != nil {
+			t.Fatalf("GetWeather returned an error: %v", err)
+		}
+
+		config.Command = []string{"false"}
+		weather, err := provider.GetWeather(config)
+		if err != nil {
+			t.Fatalf("Expected the cached weather, got error: %v", err)
+		}
+		assert.Equal(t, 1.0, weather.Current.TempC)
+	})
+
+	t.Run("Captures stderr", func(t *testing.T) {
+		config := &Config{NoCache: true, Command: []string{"sh", "-c", "echo 'station offline' >&2; exit 3"}}
+
+		_, err := provider.GetWeather(config)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "exit status 3")
+			assert.Contains(t, err.Error(), "station offline")
+		}
+	})
+
+	t.Run("Times out", func(t *testing.T) {
+		config := &Config{NoCache: true, CommandTimeout: 1, Command: []string{"sleep", "5"}}
+
+		_, err := provider.GetWeather(config)
+		assert.EqualError(t, err, "command timed out after 1s")
+	})
+
+	t.Run("Times out with a child process keeping the output open", func(t *testing.T) {
+		config := &Config{NoCache: true, CommandTimeout: 1, Command: []string{"sh", "-c", "sleep 4; echo done"}}
+
+		start := time.Now()
+		_, err := provider.GetWeather(config)
+		assert.EqualError(t, err, "command timed out after 1s")
+		assert.Less(t, time.Since(start), 3*time.Second)
+	})
+
+	t.Run("Invalid output", func(t *testing.T) {
+		config := &Config{NoCache: true, Command: []string{"echo", "not json"}}
+
+		_, err := provider.GetWeather(config)
+		if assert.Error(t, err) {
+			assert.True(t, strings.HasPrefix(err.Error(), "failed to decode command output"), err.Error())
+		}
+	})
+
+	t.Run("Missing command", func(t *testing.T) {
+		_, err := provider.GetWeather(&Config{NoCache: true})
+		assert.EqualError(t, err, "command is not set in the config")
+	})
+}
@@ -8,7 +8,9 @@ import (
 	"log/syslog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 	"github.com/spf13/cobra"
@@ -50,31 +52,93 @@ func (cp *ConfigPath) GetPath() string {
 
 // Config holds the application configuration.
 type Config struct {
-	APIKey         string  `json:"apiKey,omitempty"`
-	Location       string  `json:"location"`
-	Logger         bool    `json:"logger"`
-	Output         string  `json:"output,omitempty"`
-	ShortTmpl      string  `json:"short_template,omitempty"`
-	CurrentTmpl    string  `json:"current_template,omitempty"`
-	ForecastTmpl   string  `json:"forecast_template,omitempty"`
-	ForecastHours  int     `json:"forecastHours,omitempty"`
-	NoCache        bool    `json:"noCache,omitempty"`
+	APIKey                   string             `json:"apiKey,omitempty"`
+	OpenWeatherMapAPIKey     string             `json:"openweathermap_api_key,omitempty"`
+	Provider                 string             `json:"provider,omitempty"`
+	Providers                []string           `json:"providers,omitempty"`
+	ProviderCooldown         int                `json:"provider_cooldown,omitempty"`
+	UserAgent                string             `json:"user_agent,omitempty"`
+	Command                  []string           `json:"command,omitempty"`
+	CommandTimeout           int                `json:"command_timeout,omitempty"`
+	StationForecastProvider  string             `json:"station_forecast_provider,omitempty"`
+	ReplayDir                string             `json:"replay_dir,omitempty"`
+	RecordDir                string             `json:"-"`
+	Location                 string             `json:"location"`
+	DefaultLocation          string             `json:"default_location,omitempty"`
+	Locations                map[string]string  `json:"locations,omitempty"`
+	CompareLocations         []string           `json:"-"`
+	Logger                   bool               `json:"logger"`
+	Output                   string             `json:"output,omitempty"`
+	ShortTmpl                string             `json:"short_template,omitempty"`
+	CurrentTmpl              string             `json:"current_template,omitempty"`
+	ForecastTmpl             string             `json:"forecast_template,omitempty"`
+	DailyTmpl                string             `json:"daily_template,omitempty"`
+	AstroTmpl                string             `json:"astro_template,omitempty"`
+	MarineTmpl               string             `json:"marine_template,omitempty"`
+	ForecastHours            int                `json:"forecastHours,omitempty"`
+	ForecastDays             int                `json:"forecast_days,omitempty"`
+	Lang                     string             `json:"lang,omitempty"`
+	Locale                   string             `json:"locale,omitempty"`
+	TimeFormat               string             `json:"time_format,omitempty"`
+	LocalTime                bool               `json:"local_time,omitempty"`
+	Units                    string             `json:"units,omitempty"`
+	Alerts                   bool               `json:"alerts,omitempty"`
+	AQI                      bool               `json:"aqi,omitempty"`
+	Marine                   bool               `json:"marine,omitempty"`
+	NoCache                  bool               `json:"noCache,omitempty"`
 }
 
 // SetDefaults sets the default values for the configuration.
 func (c *Config) SetDefaults() {
 	if c.ShortTmpl == "" {
-		c.ShortTmpl = "{{printf \"%.1f\" .TempC}}° {{.Emoji}}"
+		c.ShortTmpl = "{{printf \"%.1f\" .Temp}}° {{.Emoji}}"
 	}
 	if c.CurrentTmpl == "" {
-		c.CurrentTmpl = "{{.Emoji}} {{printf \"%.1f\" .TempC}}°\n{{.Location}} - {{.Country}}"
+		c.CurrentTmpl = "{{.Emoji}} {{printf \"%.1f\" .Temp}}°\n{{.Location}} - {{.Country}}"
 	}
 	if c.ForecastTmpl == "" {
-		c.ForecastTmpl = "{{.Emoji}} {{printf \"%5.1f\" .TempC}}° [{{printf \"%5.1f\" .FeelslikeC}}°]"
+		c.ForecastTmpl = "{{.Emoji}} {{printf \"%5.1f\" .Temp}}° [{{printf \"%5.1f\" .FeelsLike}}°]"
+	}
+	if c.DailyTmpl == "" {
+		c.DailyTmpl = "{{.Emoji}} {{printf \"%5.1f\" .MinTemp}}° - {{printf \"%5.1f\" .MaxTemp}}°"
+	}
+	if c.AstroTmpl == "" {
+		c.AstroTmpl = "🌅 {{.Sunrise}} 🌇 {{.Sunset}}\n{{.MoonEmoji}} {{.MoonPhase}} ({{.MoonIllumination}}%)"
+	}
+	if c.MarineTmpl == "" {
+		c.MarineTmpl = "🌊 {{printf \"%.1f\" .WaveHeight}}{{.HeightUnit}} ({{printf \"%.1f\" .SwellHeight}}{{.HeightUnit}} {{.SwellDir}} {{printf \"%.0f\" .SwellPeriodS}}s) 🌡 {{printf \"%.1f\" .WaterTemp}}°"
 	}
 	if c.Output == "" {
 		c.Output = "table"
 	}
+	if c.Provider == "" {
+		c.Provider = defaultProvider
+	}
+}
+
+// ProviderChain returns the ordered list of providers to try.
+// The providers list takes precedence over the single provider key.
+func (c *Config) ProviderChain() []string {
+	if len(c.Providers) > 0 {
+		return c.Providers
+	}
+	return []string{c.Provider}
+}
+
+// ProviderCooldownDuration returns how long a failed provider is skipped by the fallback chain.
+func (c *Config) ProviderCooldownDuration() time.Duration {
+	if c.ProviderCooldown > 0 {
+		return time.Duration(c.ProviderCooldown) * time.Minute
+	}
+	return defaultProviderCooldown
+}
+
+// ForecastDaysToFetch returns how many days of forecast to request from a provider offering up to limit days.
+// Enough days are requested to cover both the daily forecast and the hourly one, which may start
+// late in the day and run past midnight, with a minimum of two days.
+func (c *Config) ForecastDaysToFetch(limit int) int {
+	hourlyDays := c.ForecastHours/24 + 2
+	return min(max(c.ForecastDays, hourlyDays), limit)
 }
 
 // MergeConfigs merges the custom configuration into the current configuration.
@@ -83,10 +147,61 @@ func (c *Config) MergeConfigs(customConfig *Config) {
 		c.APIKey = customConfig.APIKey
 	}
 
+	if customConfig.OpenWeatherMapAPIKey != "" {
+		c.OpenWeatherMapAPIKey = customConfig.OpenWeatherMapAPIKey
+	}
+
+	if customConfig.Provider != "" {
+		c.Provider = customConfig.Provider
+	}
+
+	if len(customConfig.Providers) > 0 {
+		c.Providers = customConfig.Providers
+	}
+
+	if customConfig.ProviderCooldown > 0 {
+		c.ProviderCooldown = customConfig.ProviderCooldown
+	}
+
+	if customConfig.UserAgent != "" {
+		c.UserAgent = customConfig.UserAgent
+	}
+
+	if len(customConfig.Command) > 0 {
+		c.Command = customConfig.Command
+	}
+
+	if customConfig.CommandTimeout > 0 {
+		c.CommandTimeout = customConfig.CommandTimeout
+	}
+
+	if customConfig.StationForecastProvider != "" {
+		c.StationForecastProvider = customConfig.StationForecastProvider
+	}
+
+	if customConfig.ReplayDir != "" {
+		c.ReplayDir = customConfig.ReplayDir
+	}
+
 	if customConfig.Location != "" {
 		c.Location = customConfig.Location
 	}
 
+	// A custom location replaces the inherited default location, which would take precedence over it
+	if customConfig.DefaultLocation != "" {
+		c.DefaultLocation = customConfig.DefaultLocation
+	} else if customConfig.Location != "" {
+		c.DefaultLocation = ""
+	}
+
+	// The saved locations of both configs are kept, the custom ones win
+	for name, location := range customConfig.Locations {
+		if c.Locations == nil {
+			c.Locations = map[string]string{}
+		}
+		c.Locations[name] = location
+	}
+
 	c.Logger = customConfig.Logger
 
 	if customConfig.ShortTmpl != "" {
@@ -98,6 +213,51 @@ func (c *Config) MergeConfigs(customConfig *Config) {
 	if customConfig.ForecastTmpl != "" {
 		c.ForecastTmpl = customConfig.ForecastTmpl
 	}
+	if customConfig.DailyTmpl != "" {
+		c.DailyTmpl = customConfig.DailyTmpl
+	}
+	if customConfig.AstroTmpl != "" {
+		c.AstroTmpl = customConfig.AstroTmpl
+	}
+	if customConfig.MarineTmpl != "" {
+		c.MarineTmpl = customConfig.MarineTmpl
+	}
+
+	if customConfig.ForecastDays > 0 {
+		c.ForecastDays = customConfig.ForecastDays
+	}
+
+	if customConfig.Lang != "" {
+		c.Lang = customConfig.Lang
+	}
+
+	if customConfig.Locale != "" {
+		c.Locale = customConfig.Locale
+	}
+
+	if customConfig.TimeFormat != "" {
+		c.TimeFormat = customConfig.TimeFormat
+	}
+
+	if customConfig.LocalTime {
+		c.LocalTime = customConfig.LocalTime
+	}
+
+	if customConfig.Units != "" {
+		c.Units = customConfig.Units
+	}
+
+	if customConfig.Alerts {
+		c.Alerts = customConfig.Alerts
+	}
+
+	if customConfig.AQI {
+		c.AQI = customConfig.AQI
+	}
+
+	if customConfig.Marine {
+		c.Marine = customConfig.Marine
+	}
 	
 	
 
@@ -116,13 +276,66 @@ func (c *Config) ParseCommand(cmd *cobra.Command, args []string, isTerminal bool
 	c.ForecastHours, _ = cmd.Flags().GetInt("forecast-hours")
 	c.Output, _ =cmd.Flags().GetString("output")
 	c.NoCache, _ = cmd.Flags().GetBool("no-cache")
+	if cmd.Flags().Changed("forecast-days") {
+		c.ForecastDays, _ = cmd.Flags().GetInt("forecast-days")
+	}
+	if cmd.Flags().Changed("lang") {
+		c.Lang, _ = cmd.Flags().GetString("lang")
+	}
+	if cmd.Flags().Changed("locale") {
+		c.Locale, _ = cmd.Flags().GetString("locale")
+	}
+	if cmd.Flags().Changed("time-format") {
+		c.TimeFormat, _ = cmd.Flags().GetString("time-format")
+	}
+	if cmd.Flags().Changed("local-time") {
+		c.LocalTime, _ = cmd.Flags().GetBool("local-time")
+	}
+	if cmd.Flags().Changed("units") {
+		c.Units, _ = cmd.Flags().GetString("units")
+	}
+	if cmd.Flags().Changed("alerts") {
+		c.Alerts, _ = cmd.Flags().GetBool("alerts")
+	}
+	if cmd.Flags().Changed("aqi") {
+		c.AQI, _ = cmd.Flags().GetBool("aqi")
+	}
+	if cmd.Flags().Changed("marine") {
+		c.Marine, _ = cmd.Flags().GetBool("marine")
+	}
+	if cmd.Flags().Changed("provider") {
+		providers, _ := cmd.Flags().GetString("provider")
+		c.Providers = nil
+		for _, name := range strings.Split(providers, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.Providers = append(c.Providers, name)
+			}
+		}
+		c.Provider = ""
+		if len(c.Providers) > 0 {
+			c.Provider = c.Providers[0]
+		}
+	}
+	if cmd.Flags().Changed("replay") {
+		c.ReplayDir, _ = cmd.Flags().GetString("replay")
+		c.Providers = []string{"replay"}
+		c.Provider = "replay"
+	}
+	c.RecordDir, _ = cmd.Flags().GetString("record")
 	if !isTerminal {
 		c.Output = "json"
 	}
 
-	if len(args) > 0 {
+	if compare, _ := cmd.Flags().GetBool("compare"); compare {
+		// Each argument is a location of its own, resolved when its weather is fetched
+		c.CompareLocations = append([]string{}, args...)
+	} else if len(args) > 0 {
 		c.Location = strings.Join(args, " ")
+	} else if c.DefaultLocation != "" {
+		c.Location = c.DefaultLocation
 	}
+	// Resolve the saved location names before the providers build their cache keys from the location
+	c.Location = c.ResolveLocation(c.Location)
 
 	// Configure syslog if enabled
 	if c.Logger {
@@ -136,6 +349,34 @@ func (c *Config) ParseCommand(cmd *cobra.Command, args []string, isTerminal bool
 	}
 }
 
+// ResolveLocation returns the location saved under the given name in the locations key, matched
+// without regard to case, or the name itself when no location is saved under it.
+// An exact match wins; among names differing only in case, the first in sorted order is used.
+func (c *Config) ResolveLocation(name string) string {
+	if savedName, ok := c.savedLocationName(name); ok {
+		return c.Locations[savedName]
+	}
+	return name
+}
+
+// savedLocationName returns the name in the locations key that the given name refers to.
+func (c *Config) savedLocationName(name string) (string, bool) {
+	if _, ok := c.Locations[name]; ok {
+		return name, true
+	}
+	savedNames := make([]string, 0, len(c.Locations))
+	for savedName := range c.Locations {
+		savedNames = append(savedNames, savedName)
+	}
+	sort.Strings(savedNames)
+	for _, savedName := range savedNames {
+		if strings.EqualFold(savedName, name) {
+			return savedName, true
+		}
+	}
+	return "", false
+}
+
 // LoadOrCreateConfig loads a configuration from a given path if it exists,
 // otherwise it creates a new one.
 // path: The path to the configuration file.
@@ -267,6 +508,40 @@ func GetUserInput(prompt string, defaultValue string, secret bool, canBeEmpty bo
 	return input, nil
 }
 
+// SaveLocation sets the location in the configuration file at the given path, keeping its other settings,
+// including the keys Config does not know. The default_location key takes precedence over the location key,
+// so it is the one set when the file has it. The saved locations are left as they are, even when
+// default_location names one of them. It returns the key that was set.
+func SaveLocation(path string, location string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return "", err
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", err
+	}
+
+	key := "location"
+	if config.DefaultLocation != "" {
+		key = "default_location"
+	}
+	settings[key], err = json.Marshal(location)
+	if err != nil {
+		return "", err
+	}
+
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return key, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
 // WriteConfig writes the given Config struct to a file at the specified path.
 // It creates the directory if it doesn't exist.
 // config: The Config struct to write to the file.
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
-	
+	"reflect"
+	"time"
+
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -172,6 +174,9 @@ func TestSetDefaults(t *testing.T) {
 	if config.ForecastTmpl == "" {
 		t.Errorf("Expected ForecastTmpl to have a default value")
 	}
+	if config.DailyTmpl == "" {
+		t.Errorf("Expected DailyTmpl to have a default value")
+	}
 
 	// Verify Table defaults
 	
@@ -236,4 +241,212 @@ func TestParseCommand(t *testing.T) {
 	if config.Output != "json" {
 		t.Errorf("Expected Output to be 'json', got '%s'", config.Output)
 	}
-}
\ No newline at end of file
+}
+func TestParseCommand_Provider(t *testing.T) {
+	config := &Config{Provider: "openmeteo"}
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("provider", "p", "", "Weather provider")
+
+	// The configured provider is kept when the flag is not given
+	config.ParseCommand(cmd, nil, true)
+	if config.Provider != "openmeteo" {
+		t.Errorf("Expected Provider to be 'openmeteo', got '%s'", config.Provider)
+	}
+
+	cmd.Flags().Set("provider", "weatherapi")
+	config.ParseCommand(cmd, nil, true)
+	if config.Provider != "weatherapi" {
+		t.Errorf("Expected Provider to be 'weatherapi', got '%s'", config.Provider)
+	}
+
+	// A comma separated list sets up a fallback chain
+	cmd.Flags().Set("provider", "weatherapi,metno")
+	config.ParseCommand(cmd, nil, true)
+	if chain := config.ProviderChain(); len(chain) != 2 || chain[0] != "weatherapi" || chain[1] != "metno" {
+		t.Errorf("Expected ProviderChain to be [weatherapi metno], got %v", chain)
+	}
+
+	// Spaces around the names and empty names are ignored
+	cmd.Flags().Set("provider", " weatherapi, openmeteo,,")
+	config.ParseCommand(cmd, nil, true)
+	if chain := config.ProviderChain(); !reflect.DeepEqual(chain, []string{"weatherapi", "openmeteo"}) {
+		t.Errorf("Expected ProviderChain to be [weatherapi openmeteo], got %v", chain)
+	}
+	if config.Provider != "weatherapi" {
+		t.Errorf("Expected Provider to be 'weatherapi', got '%s'", config.Provider)
+	}
+}
+
+func TestProviderChain(t *testing.T) {
+	config := &Config{Provider: "metno"}
+	if chain := config.ProviderChain(); len(chain) != 1 || chain[0] != "metno" {
+		t.Errorf("Expected ProviderChain to be [metno], got %v", chain)
+	}
+
+	config.Providers = []string{"openmeteo", "nws"}
+	if chain := config.ProviderChain(); len(chain) != 2 || chain[0] != "openmeteo" {
+		t.Errorf("Expected ProviderChain to be [openmeteo nws], got %v", chain)
+	}
+
+	if config.ProviderCooldownDuration() != defaultProviderCooldown {
+		t.Errorf("Expected the default cool-down, got %v", config.ProviderCooldownDuration())
+	}
+	config.ProviderCooldown = 5
+	if config.ProviderCooldownDuration() != 5*time.Minute {
+		t.Errorf("Expected a 5 minute cool-down, got %v", config.ProviderCooldownDuration())
+	}
+}
+
+func TestParseCommand_ForecastDays(t *testing.T) {
+	config := &Config{ForecastDays: 3}
+	cmd := &cobra.Command{}
+	cmd.Flags().IntP("forecast-days", "d", 0, "Number of forecast days")
+
+	// The configured days are kept when the flag is not given
+	config.ParseCommand(cmd, nil, true)
+	if config.ForecastDays != 3 {
+		t.Errorf("Expected ForecastDays to be 3, got %d", config.ForecastDays)
+	}
+
+	cmd.Flags().Set("forecast-days", "0")
+	config.ParseCommand(cmd, nil, true)
+	if config.ForecastDays != 0 {
+		t.Errorf("Expected ForecastDays to be 0, got %d", config.ForecastDays)
+	}
+}
+
+func TestParseCommand_Units(t *testing.T) {
+	config := &Config{Units: "uk-hybrid"}
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("units", "u", "", "Units")
+
+	// The configured units are kept when the flag is not given
+	config.ParseCommand(cmd, nil, true)
+	if config.Units != "uk-hybrid" {
+		t.Errorf("Expected Units to be uk-hybrid, got %s", config.Units)
+	}
+
+	cmd.Flags().Set("units", "imperial")
+	config.ParseCommand(cmd, nil, true)
+	if config.Units != "imperial" {
+		t.Errorf("Expected Units to be imperial, got %s", config.Units)
+	}
+}
+
+func TestParseCommand_Locations(t *testing.T) {
+	config := &Config{
+		Location:        "Brussels",
+		DefaultLocation: "home",
+		Locations:       map[string]string{"home": "50.8467,4.3525", "office": "51.2194,4.4025"},
+	}
+	cmd := &cobra.Command{}
+
+	// Without arguments, the default location is used
+	config.ParseCommand(cmd, nil, true)
+	if config.Location != "50.8467,4.3525" {
+		t.Errorf("Expected the default location to resolve to its coordinates, got %s", config.Location)
+	}
+
+	// Saved names are resolved without regard to case
+	config.ParseCommand(cmd, []string{"Office"}, true)
+	if config.Location != "51.2194,4.4025" {
+		t.Errorf("Expected office to resolve to its coordinates, got %s", config.Location)
+	}
+
+	// Other locations are kept as they are
+	config.ParseCommand(cmd, []string{"New", "York"}, true)
+	if config.Location != "New York" {
+		t.Errorf("Expected Location to be New York, got %s", config.Location)
+	}
+
+	// The default location may be a location itself
+	config.DefaultLocation = "Paris"
+	config.ParseCommand(cmd, nil, true)
+	if config.Location != "Paris" {
+		t.Errorf("Expected Location to be Paris, got %s", config.Location)
+	}
+}
+
+func TestParseCommand_Compare(t *testing.T) {
+	config := &Config{Location: "Brussels"}
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("compare", false, "")
+
+	// Without --compare, the arguments are a single location
+	config.ParseCommand(cmd, []string{"New", "York"}, true)
+	if config.Location != "New York" || config.CompareLocations != nil {
+		t.Errorf("Expected a single location New York, got %q and %v", config.Location, config.CompareLocations)
+	}
+
+	cmd.Flags().Set("compare", "true")
+	config.ParseCommand(cmd, []string{"home", "New York"}, true)
+	if !reflect.DeepEqual(config.CompareLocations, []string{"home", "New York"}) {
+		t.Errorf("Expected every argument to be a location to compare, got %v", config.CompareLocations)
+	}
+}
+
+func TestMergeConfigs_Locations(t *testing.T) {
+	baseConfig := &Config{Locations: map[string]string{"home": "50.8467,4.3525", "office": "51.2194,4.4025"}}
+	customConfig := &Config{DefaultLocation: "mum", Locations: map[string]string{"office": "52.3676,4.9041", "mum": "37.9838,23.7275"}}
+
+	baseConfig.MergeConfigs(customConfig)
+
+	if baseConfig.DefaultLocation != "mum" {
+		t.Errorf("Expected DefaultLocation to be 'mum', got '%s'", baseConfig.DefaultLocation)
+	}
+	// Saved locations of both configs are kept, the custom ones win
+	expected := map[string]string{"home": "50.8467,4.3525", "office": "52.3676,4.9041", "mum": "37.9838,23.7275"}
+	if !reflect.DeepEqual(baseConfig.Locations, expected) {
+		t.Errorf("Expected Locations to be %v, got %v", expected, baseConfig.Locations)
+	}
+}
+
+func TestMergeConfigs_CustomLocation(t *testing.T) {
+	baseConfig := &Config{Location: "Brussels", DefaultLocation: "home", Locations: map[string]string{"home": "50.8467,4.3525"}}
+	customConfig := &Config{Location: "Paris"}
+
+	// The location of the custom config wins over the inherited default location
+	baseConfig.MergeConfigs(customConfig)
+	baseConfig.ParseCommand(&cobra.Command{}, nil, true)
+	if baseConfig.Location != "Paris" {
+		t.Errorf("Expected Location to be Paris, got %s", baseConfig.Location)
+	}
+}
+
+func TestResolveLocation(t *testing.T) {
+	config := &Config{Locations: map[string]string{"Home": "50.8467,4.3525", "home": "51.2194,4.4025", "HOME": "52.3676,4.9041"}}
+	if location := config.ResolveLocation("home"); location != "51.2194,4.4025" {
+		t.Errorf("Expected the exact match, got %s", location)
+	}
+	// Without an exact match, the first name in sorted order wins, every time
+	for i := 0; i < 10; i++ {
+		if location := config.ResolveLocation("hOmE"); location != "52.3676,4.9041" {
+			t.Fatalf("Expected the location saved as HOME, got %s", location)
+		}
+	}
+	if location := config.ResolveLocation("Paris"); location != "Paris" {
+		t.Errorf("Expected an unsaved location to be kept, got %s", location)
+	}
+}
+
+func TestForecastDaysToFetch(t *testing.T) {
+	config := &Config{}
+	if days := config.ForecastDaysToFetch(14); days != 2 {
+		t.Errorf("Expected at least 2 days to be fetched, got %d", days)
+	}
+
+	config.ForecastDays = 7
+	if days := config.ForecastDaysToFetch(14); days != 7 {
+		t.Errorf("Expected 7 days to be fetched, got %d", days)
+	}
+	if days := config.ForecastDaysToFetch(5); days != 5 {
+		t.Errorf("Expected the provider limit of 5 days, got %d", days)
+	}
+
+	// Enough days are fetched for the hourly forecast
+	config.ForecastDays = 0
+	config.ForecastHours = 48
+	if days := config.ForecastDaysToFetch(14); days != 4 {
+		t.Errorf("Expected 4 days to be fetched for 48 hours, got %d", days)
+	}
+}
@@ -9,84 +9,309 @@ import (
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
 )
 
+// alertGlyph marks the active weather alerts in the output.
+const alertGlyph = "⚠"
+
 // FormatOutput formats the weather data based on the specified output type in the config.
 func FormatOutput(weather *Weather, config *Config, nowFunc func() time.Time) (string, error) {
 
+	units, err := ParseUnits(config.Units)
+	if err != nil {
+		return "", err
+	}
+	locale := ParseLocale(config.Locale, config.TimeFormat)
+	locale.zone = outputZone(weather, config)
+
 	if config.Output == "json" {
-		return formatJSON(weather, config, nowFunc)
+		return formatJSON(weather, config, units, locale, nowFunc)
 	}
-	return formatTable(weather, config, nowFunc)
+	return formatTable(weather, config, units, locale, nowFunc)
 }
 
 // formatTable formats the weather data into a human-readable table.
-func formatTable(weather *Weather, config *Config, nowFunc func() time.Time) (string, error) {
+func formatTable(weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) (string, error) {
 
 	t := table.NewWriter()
 	t.SetStyle(table.StyleLight)
 
 	// Current section
-	t.AppendRow(table.Row{"Current:"})
+	t.AppendRow(table.Row{translate(config.Lang, "Current:")})
 	t.AppendSeparator()
 
-	currentLine, err := renderTemplateToString("table-current", config.CurrentTmpl, weather.Current)
+	currentLine, err := renderTemplateToString("table-current", config.CurrentTmpl, newCurrentView(weather, units, locale, nowFunc), locale)
 	if err != nil {
 		return "", fmt.Errorf("error rendering location template: %w", err)
 	}
 	t.AppendRow(table.Row{currentLine})
 
+	// Alerts section
+	renderAlerts(t, weather, config, locale, nowFunc)
+
 	// Hourly Forecast section
-	if err := renderHourlyForecast(t, weather, config, nowFunc); err != nil {
+	if err := renderHourlyForecast(t, weather, config, units, locale, nowFunc); err != nil {
+		return "", err
+	}
+
+	// Marine section
+	if err := renderMarine(t, weather, config, units, locale, nowFunc); err != nil {
+		return "", err
+	}
+
+	// Daily Forecast section
+	if err := renderDailyForecast(t, weather, config, units, locale, nowFunc); err != nil {
+		return "", err
+	}
+
+	// Astronomy section
+	if err := renderAstronomy(t, weather, config, locale, nowFunc); err != nil {
 		return "", err
 	}
 
 	return t.Render(), nil
 }
 
+// renderAlerts renders the active weather alerts section of the table.
+func renderAlerts(t table.Writer, weather *Weather, config *Config, locale LocaleFormat, nowFunc func() time.Time) {
+
+	alerts := weather.ActiveAlerts(nowFunc())
+	if len(alerts) == 0 {
+		return
+	}
+
+	t.AppendSeparator()
+	t.AppendRow(table.Row{translate(config.Lang, "Alerts:")})
+	t.AppendSeparator()
+
+	for _, alert := range alerts {
+		t.AppendRow(table.Row{fmt.Sprintf("%s %s", alertGlyph, alertSummary(alert, config.Lang, locale))})
+	}
+}
+
+// alertSummary returns the one line summary of an alert: its severity, headline and expiry.
+func alertSummary(alert WeatherAlert, lang string, locale LocaleFormat) string {
+	summary := alert.Headline
+	if alert.Severity != "" {
+		summary = alert.Severity + ": " + summary
+	}
+	if alert.ExpiresEpoch != 0 {
+		summary += ", " + translate(lang, "until %s", locale.formatDateTime(time.Unix(alert.ExpiresEpoch, 0)))
+	}
+	return summary
+}
+
 // renderHourlyForecast renders the hourly forecast section of the table.
-func renderHourlyForecast(t table.Writer, weather *Weather, config *Config, nowFunc func() time.Time) error {
+func renderHourlyForecast(t table.Writer, weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) error {
 
 	if config.ForecastHours > 0 {
 		t.AppendSeparator()
-		t.AppendRow(table.Row{"Hourly Forecast:"})
+		t.AppendRow(table.Row{translate(config.Lang, "Hourly Forecast:")})
 		t.AppendSeparator()
 
 		hoursCount := 0
+		lastDate := ""
 		for _, hour := range weather.HourlyForecast {
 			if hoursCount >= config.ForecastHours {
 				break
 			}
-			timeVal := time.Unix(hour.TimeEpoch, 0)
+			timeVal := time.Unix(hour.TimeEpoch, 0).In(locale.zone)
 			if timeVal.Before(nowFunc()) {
 				continue
 			}
 
-			hourlyLineContent, err := renderTemplateToString("table-hourly", config.ForecastTmpl, hour)
+			// Separate the days when the forecast runs past midnight
+			date := timeVal.Format("2006-01-02")
+			if lastDate != "" && date != lastDate {
+				t.AppendSeparator()
+				t.AppendRow(table.Row{locale.formatDay(timeVal)})
+				t.AppendSeparator()
+			}
+			lastDate = date
+
+			hourlyLineContent, err := renderTemplateToString("table-hourly", config.ForecastTmpl, hourView{hour, units, weather.TimeZone()}, locale)
 			if err != nil {
 				return fmt.Errorf("error rendering hourly template: %w", err)
 			}
-			hourlyLine := fmt.Sprintf("%s : %s", timeVal.Format("15:04"), hourlyLineContent)
+			hourlyLine := fmt.Sprintf("%s : %s", timeVal.Format(locale.clock), hourlyLineContent)
 			t.AppendRow(table.Row{hourlyLine})
 			hoursCount++
+		}
+	}
+	return nil
+}
 
-			if timeVal.After(nowFunc().Add(time.Hour * 23)) {
-				break
-			}
+// renderMarine renders the sea conditions of the coming hours and the tides of the coming day,
+// when the marine forecast was fetched. At least the sea conditions of the next hour are shown.
+func renderMarine(t table.Writer, weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) error {
+
+	if weather.Marine == nil {
+		return nil
+	}
+
+	t.AppendSeparator()
+	t.AppendRow(table.Row{translate(config.Lang, "Marine:")})
+	t.AppendSeparator()
+
+	hoursCount := 0
+	for _, hour := range weather.Marine.Hourly {
+		if hoursCount >= max(config.ForecastHours, 1) {
+			break
+		}
+		timeVal := time.Unix(hour.TimeEpoch, 0).In(locale.zone)
+		if timeVal.Before(nowFunc()) {
+			continue
+		}
+
+		marineLineContent, err := renderTemplateToString("table-marine", config.MarineTmpl, marineView{hour, units, weather.TimeZone()}, locale)
+		if err != nil {
+			return fmt.Errorf("error rendering marine template: %w", err)
+		}
+		t.AppendRow(table.Row{fmt.Sprintf("%s : %s", timeVal.Format(locale.clock), marineLineContent)})
+		hoursCount++
+	}
+
+	for _, tide := range weather.Marine.UpcomingTides(nowFunc()) {
+		t.AppendRow(table.Row{tideSummary(tide, config.Lang, units, locale)})
+	}
+	return nil
+}
+
+// tideSummary returns the one line summary of a tide: whether it is high or low, its time and its height.
+func tideSummary(tide WeatherTide, lang string, units Units, locale LocaleFormat) string {
+	clock := time.Unix(tide.TimeEpoch, 0).In(locale.zone).Format(locale.clock)
+	height := locale.printf("%.1f%s", units.convertHeight(tide.HeightM), units.HeightUnit())
+	if tide.High {
+		return "▲ " + translate(lang, "High tide %s, %s", clock, height)
+	}
+	return "▼ " + translate(lang, "Low tide %s, %s", clock, height)
+}
+
+// renderDailyForecast renders the daily forecast section of the table.
+func renderDailyForecast(t table.Writer, weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) error {
+
+	days := upcomingDays(weather, config, nowFunc)
+	if len(days) == 0 {
+		return nil
+	}
+
+	t.AppendSeparator()
+	t.AppendRow(table.Row{translate(config.Lang, "Daily Forecast:")})
+	t.AppendSeparator()
+
+	for _, day := range days {
+		dailyLineContent, err := renderTemplateToString("table-daily", config.DailyTmpl, newDayView(day, units, locale), locale)
+		if err != nil {
+			return fmt.Errorf("error rendering daily template: %w", err)
+		}
+		t.AppendRow(table.Row{fmt.Sprintf("%s : %s", dayLabel(day, locale), dailyLineContent)})
+	}
+	return nil
+}
+
+// upcomingDays returns the days of the daily forecast to display, starting from today.
+func upcomingDays(weather *Weather, config *Config, nowFunc func() time.Time) []DailyForecast {
+
+	days := []DailyForecast{}
+	today := nowFunc().In(weather.TimeZone()).Format("2006-01-02")
+	for _, day := range weather.DailyForecast {
+		if len(days) >= config.ForecastDays {
+			break
+		}
+		if day.Date < today {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// dayLabel returns the short label of a forecast day in the layout of the locale, e.g. "Mon 13".
+func dayLabel(day DailyForecast, locale LocaleFormat) string {
+	date, err := time.Parse("2006-01-02", day.Date)
+	if err != nil {
+		return day.Date
+	}
+	return locale.formatDay(date)
+}
+
+// renderAstronomy renders the astronomy section of the table, when the provider reports today's astronomy data.
+func renderAstronomy(t table.Writer, weather *Weather, config *Config, locale LocaleFormat, nowFunc func() time.Time) error {
+
+	today := todaysForecast(weather, nowFunc)
+	if config.AstroTmpl == "" || today == nil || (today.Astro.SunriseEpoch == 0 && today.Astro.MoonPhase == "") {
+		return nil
+	}
+
+	t.AppendSeparator()
+	t.AppendRow(table.Row{translate(config.Lang, "Astronomy:")})
+	t.AppendSeparator()
+
+	astroLine, err := renderTemplateToString("table-astro", config.AstroTmpl, today.Astro.inLocale(locale), locale)
+	if err != nil {
+		return fmt.Errorf("error rendering astronomy template: %w", err)
+	}
+	t.AppendRow(table.Row{astroLine})
+	return nil
+}
+
+// currentView is the data of the current and short templates: the current conditions
+// together with today's astronomy data, the display units and the time of the location.
+type currentView struct {
+	WeatherCurrent
+	WeatherAstro
+	Units
+	TzID string
+	now  time.Time
+	zone *time.Location
+}
+
+// LocationTime returns the current time in the time zone of the location.
+func (v currentView) LocationTime() time.Time { return v.now.In(v.zone) }
+
+// LocalTime returns the current time in the time zone of the machine.
+func (v currentView) LocalTime() time.Time { return v.now.Local() }
+
+// newCurrentView returns the data of the current and short templates, with the astronomy times written in the locale.
+func newCurrentView(weather *Weather, units Units, locale LocaleFormat, nowFunc func() time.Time) currentView {
+	view := currentView{
+		WeatherCurrent: weather.Current,
+		Units:          units,
+		TzID:           weather.Location.TzID,
+		now:            nowFunc(),
+		zone:           weather.TimeZone(),
+	}
+	if today := todaysForecast(weather, nowFunc); today != nil {
+		view.WeatherAstro = today.Astro.inLocale(locale)
+	}
+	return view
+}
+
+// todaysForecast returns the daily forecast of the current day, or of the first day after it,
+// or nil if there is none.
+func todaysForecast(weather *Weather, nowFunc func() time.Time) *DailyForecast {
+	today := nowFunc().In(weather.TimeZone()).Format("2006-01-02")
+	for i, day := range weather.DailyForecast {
+		if day.Date >= today {
+			return &weather.DailyForecast[i]
 		}
 	}
 	return nil
 }
 
 // formatJSON formats the weather data into a JSON string.
-func formatJSON(weather *Weather, config *Config, nowFunc func() time.Time) (string, error) {
+func formatJSON(weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) (string, error) {
 
-	text, err := renderTemplateToString("json-text", config.ShortTmpl, weather.Current)
+	text, err := renderTemplateToString("json-text", config.ShortTmpl, newCurrentView(weather, units, locale, nowFunc), locale)
 	if err != nil {
 		return "", fmt.Errorf("error rendering json text template: %w", err)
 	}
+	if len(weather.ActiveAlerts(nowFunc())) > 0 {
+		text = alertGlyph + " " + text
+	}
 
-	tooltipContent, err := renderJSONTooltip(weather, config, nowFunc)
+	tooltipContent, err := renderJSONTooltip(weather, config, units, locale, nowFunc)
 	if err != nil {
 		return "", err
 	}
@@ -95,11 +320,17 @@ func formatJSON(weather *Weather, config *Config, nowFunc func() time.Time) (str
 	outputStruct := struct {
 		Text    string `json:"text"`
 		Tooltip string `json:"tooltip"`
+		Class   string `json:"class,omitempty"`
 	}{
 		Text:    text,
 		Tooltip: tooltipContent,
 	}
 
+	// Let waybar style the module by the air quality
+	if weather.Current.AirQuality != nil {
+		outputStruct.Class = weather.Current.AirQuality.CSSClass()
+	}
+
 	// Marshal to JSON
 	jsonOutput, err := json.Marshal(outputStruct)
 	if err != nil {
@@ -110,41 +341,219 @@ func formatJSON(weather *Weather, config *Config, nowFunc func() time.Time) (str
 }
 
 // renderJSONTooltip renders the JSON tooltip field.
-func renderJSONTooltip(weather *Weather, config *Config, nowFunc func() time.Time) (string, error) {
+func renderJSONTooltip(weather *Weather, config *Config, units Units, locale LocaleFormat, nowFunc func() time.Time) (string, error) {
 
 	tooltip := []string{}
+
+	// Active alerts come first, they matter more than the forecast
+	for _, alert := range weather.ActiveAlerts(nowFunc()) {
+		tooltip = append(tooltip, fmt.Sprintf(" %s %s ", alertGlyph, alertSummary(alert, config.Lang, locale)))
+	}
+
 	if config.ForecastHours > 0 {
 		hoursCount := 0
 		for _, hour := range weather.HourlyForecast {
 			if hoursCount >= config.ForecastHours {
 				break
 			}
-			timeVal := time.Unix(hour.TimeEpoch, 0)
+			timeVal := time.Unix(hour.TimeEpoch, 0).In(locale.zone)
 			if timeVal.Before(nowFunc()) {
 				continue
 			}
 
-			tooltipLineContent, err := renderTemplateToString("json-tooltip", config.ForecastTmpl, hour)
+			tooltipLineContent, err := renderTemplateToString("json-tooltip", config.ForecastTmpl, hourView{hour, units, weather.TimeZone()}, locale)
 			if err != nil {
 				return "", fmt.Errorf("error rendering json tooltip template: %w", err)
 			}
-			tooltipLine := fmt.Sprintf(" %s: %s ", timeVal.Format("15:04"), tooltipLineContent)
+			tooltipLine := fmt.Sprintf(" %s: %s ", timeVal.Format(locale.clock), tooltipLineContent)
 			tooltip = append(tooltip, tooltipLine)
 			hoursCount++
+		}
+	}
 
+	// Tides of the coming day, after the hourly forecast
+	if weather.Marine != nil {
+		for _, tide := range weather.Marine.UpcomingTides(nowFunc()) {
+			tooltip = append(tooltip, " "+tideSummary(tide, config.Lang, units, locale)+" ")
+		}
+	}
 
-			if timeVal.After(nowFunc().Add(time.Hour * 23)) {
-				break
+	// Daily forecast, after the hourly one
+	if days := upcomingDays(weather, config, nowFunc); len(days) > 0 {
+		tooltip = append(tooltip, " "+translate(config.Lang, "Daily Forecast:")+" ")
+		for _, day := range days {
+			tooltipLineContent, err := renderTemplateToString("json-daily", config.DailyTmpl, newDayView(day, units, locale), locale)
+			if err != nil {
+				return "", fmt.Errorf("error rendering json daily template: %w", err)
 			}
+			tooltip = append(tooltip, fmt.Sprintf(" %s: %s ", dayLabel(day, locale), tooltipLineContent))
 		}
 	}
+
+	// Report which provider answered when a fallback chain is configured
+	if len(config.ProviderChain()) > 1 {
+		tooltip = append(tooltip, " "+translate(config.Lang, "Source: %s", weather.Source.Provider)+" ")
+	}
 	return strings.Join(tooltip, "\r"), nil
 }
 
+// FormatComparison formats the weather of several locations side by side, into a table with a column
+// per location. The hourly rows are aligned on the hours of the machine's time zone, as the locations
+// may lie in different ones.
+func FormatComparison(weathers []*Weather, config *Config, nowFunc func() time.Time) (string, error) {
+
+	units, err := ParseUnits(config.Units)
+	if err != nil {
+		return "", err
+	}
+	locale := ParseLocale(config.Locale, config.TimeFormat)
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.Style().Format.Header = text.FormatDefault // keep the locations as they were given
+
+	header := table.Row{""}
+	current := table.Row{translate(config.Lang, "Current:")}
+	for i, weather := range weathers {
+		header = append(header, config.CompareLocations[i])
+		locationLocale := locale
+		locationLocale.zone = outputZone(weather, config)
+		currentLine, err := renderTemplateToString("compare-current", config.CurrentTmpl, newCurrentView(weather, units, locationLocale, nowFunc), locale)
+		if err != nil {
+			return "", fmt.Errorf("error rendering location template: %w", err)
+		}
+		current = append(current, currentLine)
+	}
+	t.AppendHeader(header)
+	t.AppendRow(current)
+
+	if config.ForecastHours > 0 {
+		t.AppendSeparator()
+		t.AppendRow(table.Row{translate(config.Lang, "Hourly Forecast:")})
+		t.AppendSeparator()
+	}
+
+	// The first row is the next full hour, unless it is one right now
+	now := nowFunc().In(locale.zone)
+	start := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, locale.zone)
+	if start.Before(now) {
+		start = start.Add(time.Hour)
+	}
+	for row := 0; row < config.ForecastHours; row++ {
+		from := start.Add(time.Duration(row) * time.Hour)
+		hourly := table.Row{from.Format(locale.clock)}
+		for _, weather := range weathers {
+			cell := ""
+			if hour := forecastHourAt(weather, from); hour != nil {
+				if cell, err = renderTemplateToString("compare-hourly", config.ForecastTmpl, hourView{*hour, units, weather.TimeZone()}, locale); err != nil {
+					return "", fmt.Errorf("error rendering hourly template: %w", err)
+				}
+			}
+			hourly = append(hourly, cell)
+		}
+		t.AppendRow(hourly)
+	}
+
+	return t.Render(), nil
+}
+
+// forecastHourAt returns the hour of the hourly forecast starting within the hour from the given time,
+// or nil if the forecast does not cover it. Locations with a half hour offset start half past.
+func forecastHourAt(weather *Weather, from time.Time) *HourlyForecast {
+	for i, hour := range weather.HourlyForecast {
+		if hour.TimeEpoch >= from.Unix() && hour.TimeEpoch < from.Add(time.Hour).Unix() {
+			return &weather.HourlyForecast[i]
+		}
+	}
+	return nil
+}
+
+// FormatAlerts formats the full descriptions of the active weather alerts.
+func FormatAlerts(weather *Weather, config *Config, nowFunc func() time.Time) string {
+
+	lang := config.Lang
+	locale := ParseLocale(config.Locale, config.TimeFormat)
+	locale.zone = outputZone(weather, config)
+
+	alerts := weather.ActiveAlerts(nowFunc())
+	if len(alerts) == 0 {
+		return translate(lang, "No active weather alerts for %s.", weather.Location.Name)
+	}
+
+	sections := []string{}
+	for _, alert := range alerts {
+		lines := []string{fmt.Sprintf("%s %s", alertGlyph, alertSummary(alert, lang, locale))}
+		if alert.Event != "" && alert.Event != alert.Headline {
+			lines = append(lines, translate(lang, "Event: %s", alert.Event))
+		}
+		if alert.Urgency != "" {
+			lines = append(lines, translate(lang, "Urgency: %s", alert.Urgency))
+		}
+		if alert.Areas != "" {
+			lines = append(lines, translate(lang, "Areas: %s", alert.Areas))
+		}
+		if alert.EffectiveEpoch != 0 {
+			lines = append(lines, translate(lang, "Effective: %s", locale.formatDateTime(time.Unix(alert.EffectiveEpoch, 0))))
+		}
+		if alert.ExpiresEpoch != 0 {
+			lines = append(lines, translate(lang, "Expires: %s", locale.formatDateTime(time.Unix(alert.ExpiresEpoch, 0))))
+		}
+		if alert.Description != "" {
+			lines = append(lines, "", strings.TrimSpace(alert.Description))
+		}
+		if alert.Instruction != "" {
+			lines = append(lines, "", strings.TrimSpace(alert.Instruction))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// FormatProviders formats the registered weather providers and their capabilities into a table.
+func FormatProviders(providers []ProviderInfo) string {
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Provider", "Capabilities", "Description"})
+
+	for _, info := range providers {
+		capabilities := make([]string, len(info.Capabilities))
+		for i, capability := range info.Capabilities {
+			capabilities[i] = string(capability)
+		}
+		t.AppendRow(table.Row{info.Name, strings.Join(capabilities, ", "), info.Description})
+	}
+	return t.Render()
+}
+
+// FormatLocations formats the locations matching a search into a numbered table.
+func FormatLocations(candidates []LocationCandidate) string {
+
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"#", "Name", "Region", "Country", "Coordinates", "ID"})
+
+	for i, candidate := range candidates {
+		t.AppendRow(table.Row{i + 1, candidate.Name, candidate.Region, candidate.Country,
+			fmt.Sprintf("%.4f, %.4f", candidate.Lat, candidate.Lon), candidate.ID})
+	}
+	return t.Render()
+}
+
+// outputZone returns the time zone the times are written in: the one of the location,
+// or the one of the machine when local_time is set.
+func outputZone(weather *Weather, config *Config) *time.Location {
+	if config.LocalTime {
+		return time.Local
+	}
+	return weather.TimeZone()
+}
+
 // renderTemplateToString parses and executes a template, returning the result as a string.
-func renderTemplateToString(templateName string, templateString string, data interface{},) (string, error) {
+// The printf function of the template writes numbers with the decimal separator of the locale.
+func renderTemplateToString(templateName string, templateString string, data interface{}, locale LocaleFormat) (string, error) {
 
-	tmpl, err := template.New(templateName).Parse(templateString)
+	tmpl, err := template.New(templateName).Funcs(template.FuncMap{"printf": locale.printf}).Parse(templateString)
 	if err != nil {
 		return "", fmt.Errorf("error creating template %s: %w", templateName, err)
 	}
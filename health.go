@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultProviderCooldown is how long a failed provider is skipped when no cool-down is configured.
+const defaultProviderCooldown = 15 * time.Minute
+
+// ProviderFailure records the last failure of a weather provider.
+type ProviderFailure struct {
+	Timestamp time.Time `json:"timestamp"`
+	Error     string    `json:"error"`
+}
+
+// ProviderHealth tracks the recent failures of the weather providers, so that a fallback
+// chain can skip them for a cool-down window. It is persisted next to the cache.
+type ProviderHealth struct {
+	Failures map[string]ProviderFailure `json:"failures"`
+	filePath string
+	mu       sync.Mutex
+}
+
+// NewProviderHealth creates a new ProviderHealth instance and loads it from disk.
+func NewProviderHealth(configPath string) (*ProviderHealth, error) {
+	healthPath := filepath.Join(filepath.Dir(configPath), "health.json")
+	health := &ProviderHealth{
+		Failures: make(map[string]ProviderFailure),
+		filePath: healthPath,
+	}
+	if err := health.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return health, nil
+}
+
+// load reads the health file from disk and unmarshals it into the ProviderHealth struct.
+func (h *ProviderHealth) load() error {
+	data, err := os.ReadFile(h.filePath)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &h.Failures)
+}
+
+// save writes the provider health to disk as a JSON file.
+func (h *ProviderHealth) save() error {
+	data, err := json.MarshalIndent(h.Failures, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.filePath, data, 0644)
+}
+
+// IsCoolingDown checks if the provider failed within the given cool-down window.
+func (h *ProviderHealth) IsCoolingDown(provider string, cooldown time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	failure, found := h.Failures[provider]
+	return found && time.Since(failure.Timestamp) < cooldown
+}
+
+// RecordFailure remembers that the provider failed and saves the health to disk.
+func (h *ProviderHealth) RecordFailure(provider string, err error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Failures[provider] = ProviderFailure{
+		Timestamp: time.Now(),
+		Error:     err.Error(),
+	}
+	return h.save()
+}
+
+// RecordSuccess forgets any previous failure of the provider and saves the health to disk.
+func (h *ProviderHealth) RecordSuccess(provider string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, found := h.Failures[provider]; !found {
+		return nil
+	}
+	delete(h.Failures, provider)
+	return h.save()
+}
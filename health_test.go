@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderHealth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	health, err := NewProviderHealth(configPath)
+	assert.NoError(t, err)
+	assert.Empty(t, health.Failures)
+	assert.False(t, health.IsCoolingDown("weatherapi", time.Minute))
+
+	// A failure is persisted next to the config and starts the cool-down window
+	err = health.RecordFailure("weatherapi", errors.New("API request failed with status code 503"))
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(filepath.Dir(configPath), "health.json"))
+
+	loaded, err := NewProviderHealth(configPath)
+	assert.NoError(t, err)
+	assert.True(t, loaded.IsCoolingDown("weatherapi", time.Minute))
+	assert.Equal(t, "API request failed with status code 503", loaded.Failures["weatherapi"].Error)
+
+	// The window passes
+	failure := loaded.Failures["weatherapi"]
+	failure.Timestamp = time.Now().Add(-2 * time.Minute)
+	loaded.Failures["weatherapi"] = failure
+	assert.False(t, loaded.IsCoolingDown("weatherapi", time.Minute))
+
+	// A success clears the failure
+	assert.NoError(t, loaded.RecordSuccess("weatherapi"))
+	assert.Empty(t, loaded.Failures)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// LocaleFormat holds how times, dates and decimal numbers are written in the output.
+type LocaleFormat struct {
+	clock     string         // layout of the hours, e.g. "15:04"
+	dayLayout string         // layout of the day labels, e.g. "Mon 02"
+	decimal   string         // decimal separator of the numbers formatted with printf
+	zone      *time.Location // time zone the times are written in
+	language  string         // language of the weekday names, from the message catalog
+}
+
+// defaultLocaleFormat is used when no locale, or an unknown one, is configured.
+var defaultLocaleFormat = LocaleFormat{clock: "15:04", dayLayout: "Mon 02", decimal: ".", zone: time.Local}
+
+// localeFormats are the known locales, keyed by language and optionally region.
+// The weekday names of the day labels are translated with the message catalog, so the
+// locales whose language has no catalog leave the weekday out.
+var localeFormats = map[string]LocaleFormat{
+	"en_us": {clock: "3:04 PM", dayLayout: "Mon 1/2", decimal: "."},
+	"en_ca": {clock: "3:04 PM", dayLayout: "Mon 2006-01-02", decimal: "."},
+	"en_au": {clock: "3:04 PM", dayLayout: "Mon 02/01", decimal: "."},
+	"en_gb": {clock: "15:04", dayLayout: "Mon 02/01", decimal: "."},
+	"de":    {clock: "15:04", dayLayout: "Mon 02.01.", decimal: ",", language: "de"},
+	"el":    {clock: "15:04", dayLayout: "Mon 02/01", decimal: ",", language: "el"},
+	"es":    {clock: "15:04", dayLayout: "Mon 02/01", decimal: ",", language: "es"},
+	"fr":    {clock: "15:04", dayLayout: "Mon 02/01", decimal: ",", language: "fr"},
+	"it":    {clock: "15:04", dayLayout: "Mon 02/01", decimal: ",", language: "it"},
+	"nl":    {clock: "15:04", dayLayout: "Mon 02-01", decimal: ",", language: "nl"},
+	"pt":    {clock: "15:04", dayLayout: "02/01", decimal: ","},
+}
+
+// ParseLocale returns the format of a locale such as "en_US" or "de-DE", falling back to its language,
+// and then to the default format. The time format, "12h", "24h" or a Go time layout, overrides the clock of the locale.
+func ParseLocale(locale string, timeFormat string) LocaleFormat {
+	format := defaultLocaleFormat
+	locale = strings.ToLower(strings.ReplaceAll(locale, "-", "_"))
+	if known, ok := localeFormats[locale]; ok {
+		format = known
+	} else if known, ok := localeFormats[strings.Split(locale, "_")[0]]; ok {
+		format = known
+	}
+	format.zone = time.Local
+
+	switch strings.ToLower(timeFormat) {
+	case "":
+	case "12h":
+		format.clock = "3:04 PM"
+	case "24h":
+		format.clock = "15:04"
+	default:
+		format.clock = timeFormat
+	}
+	return format
+}
+
+// formatDay writes the day of the time in the day layout of the locale, e.g. "Mo 13.01.",
+// with the weekday in the language of the locale.
+func (l LocaleFormat) formatDay(t time.Time) string {
+	day := t.Format(l.dayLayout)
+	if l.language == "" || !strings.Contains(l.dayLayout, "Mon") {
+		return day
+	}
+	weekday := t.Format("Mon")
+	return strings.Replace(day, weekday, translate(l.language, weekday), 1)
+}
+
+// formatDateTime writes the day and the time of the time in the time zone of the locale, e.g. "Mo 13.01. 10:00".
+func (l LocaleFormat) formatDateTime(t time.Time) string {
+	t = t.In(l.zone)
+	return l.formatDay(t) + " " + t.Format(l.clock)
+}
+
+// printf formats like fmt.Sprintf, writing the floating point numbers with the decimal separator of the locale.
+// It replaces the printf function of the templates.
+func (l LocaleFormat) printf(format string, args ...any) string {
+	if l.decimal == "." {
+		return fmt.Sprintf(format, args...)
+	}
+	localized := make([]any, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case float64:
+			localized[i] = localizedFloat{value: value, decimal: l.decimal}
+		case float32:
+			localized[i] = localizedFloat{value: float64(value), decimal: l.decimal}
+		default:
+			localized[i] = arg
+		}
+	}
+	return fmt.Sprintf(format, localized...)
+}
+
+// localizedFloat is a float64 that is formatted with another decimal separator.
+type localizedFloat struct {
+	value   float64
+	decimal string
+}
+
+// Format implements fmt.Formatter, formatting the number as fmt would and then replacing the decimal point.
+func (f localizedFloat) Format(state fmt.State, verb rune) {
+	formatted := fmt.Sprintf(fmt.FormatString(state, verb), f.value)
+	io.WriteString(state, strings.Replace(formatted, ".", f.decimal, 1))
+}
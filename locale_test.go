@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocale(t *testing.T) {
+	assert.Equal(t, defaultLocaleFormat, ParseLocale("", ""))
+	assert.Equal(t, defaultLocaleFormat, ParseLocale("tlh", ""))
+
+	us := ParseLocale("en_US", "")
+	assert.Equal(t, "3:04 PM", us.clock)
+	assert.Equal(t, ".", us.decimal)
+
+	// Regions fall back to their language
+	de := ParseLocale("de-AT", "")
+	assert.Equal(t, "15:04", de.clock)
+	assert.Equal(t, ",", de.decimal)
+	assert.Equal(t, "Mon 02.01.", de.dayLayout)
+
+	// The time format overrides the clock of the locale
+	assert.Equal(t, "15:04", ParseLocale("en_US", "24h").clock)
+	assert.Equal(t, "3:04 PM", ParseLocale("de", "12H").clock)
+	assert.Equal(t, "3:04pm", ParseLocale("", "3:04pm").clock)
+}
+
+func TestLocaleFormat_FormatDay(t *testing.T) {
+	sunday := time.Date(2025, 1, 12, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Sun 12", defaultLocaleFormat.formatDay(sunday))
+	assert.Equal(t, "Sun 1/12", ParseLocale("en_US", "").formatDay(sunday))
+	assert.Equal(t, "So 12.01.", ParseLocale("de_AT", "").formatDay(sunday))
+	assert.Equal(t, "Κυρ 12/01", ParseLocale("el", "").formatDay(sunday))
+	assert.Equal(t, "12/01", ParseLocale("pt_BR", "").formatDay(sunday), "Languages without a catalog should leave the weekday out")
+
+	de := ParseLocale("de", "")
+	de.zone = time.UTC
+	assert.Equal(t, "So 12.01. 10:00", de.formatDateTime(sunday))
+}
+
+func TestLocaleFormat_Printf(t *testing.T) {
+	de := ParseLocale("de", "")
+	assert.Equal(t, "  1,3°", de.printf("%5.1f°", 1.3))
+	assert.Equal(t, "-12,35 / 7 / 64%", de.printf("%.2f / %d / %d%%", -12.345, 7, 64))
+	assert.Equal(t, "Ab 5.", de.printf("%s %d.", "Ab", 5), "Only the numbers should be localized")
+
+	assert.Equal(t, "  1.3°", defaultLocaleFormat.printf("%5.1f°", 1.3))
+}
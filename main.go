@@ -1,8 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/mattn/go-isatty"
@@ -11,8 +17,8 @@ import (
 
 var rootCmd = &cobra.Command{
 	Use:   "wayther [Location]",
-	Short: "A simple weatherapi.com cli client",
-	Long: `wayther is a CLI tool for retrieving current weather and forecasts from weatherapi.com.
+	Short: "A simple weather cli client",
+	Long: `wayther is a CLI tool for retrieving current weather and forecasts from weatherapi.com and other weather providers (see 'wayther providers').
 
 You You can provide location as argument.
 Multiple options can be applied simultaneously.
@@ -20,7 +26,10 @@ Multiple options can be applied simultaneously.
 Configuration:
   The application uses a configuration file to store your WeatherAPI key and default location.
   If no configuration file is found, you will be prompted to create one interactively.
-  The 'logger' key in the config (boolean, defaults to false) enables syslog output if true.`,
+  The 'logger' key in the config (boolean, defaults to false) enables syslog output if true.
+  The 'provider' key (or the --provider flag) selects the weather backend, defaulting to weatherapi.
+  The 'providers' key lists several backends that are tried in order when one of them fails.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configPath, err := NewConfigPath()
 		if err != nil {
@@ -33,7 +42,12 @@ Configuration:
 			return err
 		}
 
-		weatherProvider := &weatherapiProvider{cache: cache}
+		health, err := NewProviderHealth(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		weatherProvider := &configuredProvider{cache: cache, health: health}
 		configProvider := &FileConfigProvider{}
 		isTerminal := isatty.IsTerminal(os.Stdout.Fd())
 
@@ -41,12 +55,173 @@ Configuration:
 	},
 }
 
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "List the available weather providers and their capabilities",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(FormatProviders(RegisteredProviders()))
+	},
+}
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts [Location]",
+	Short: "Print the full descriptions of the active severe weather alerts",
+	Args:  cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := NewConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath.Custom, _ = cmd.Flags().GetString("config")
+
+		cache, err := NewCache(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		health, err := NewProviderHealth(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		weatherProvider := &configuredProvider{cache: cache, health: health}
+		return runAlerts(cmd, args, configPath, weatherProvider, &FileConfigProvider{}, time.Now)
+	},
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "List the locations matching a name, and save one of them as the location",
+	Long: `List the locations matching a name, with their region, country and coordinates.
+
+A name such as "Springfield" matches many places. Save the right one with --save and its number,
+so it is the configured location from then on. It is saved by its coordinates, which every
+provider understands, or with --by id by its weatherapi.com id.
+Only providers with the search capability can be used, see 'wayther providers'.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := NewConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath.Custom, _ = cmd.Flags().GetString("config")
+
+		cache, err := NewCache(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		searcher := &configuredProvider{cache: cache}
+		return runSearch(cmd, args, configPath, searcher, &FileConfigProvider{})
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history --date YYYY-MM-DD [Location]",
+	Short: "Print the weather of a past day",
+	Long: `Print the weather of a past day, hour by hour, with the summary of the day.
+
+Past weather does not change, so days that are over are cached permanently.
+Only providers with the history capability can be used, see 'wayther providers'.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := NewConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath.Custom, _ = cmd.Flags().GetString("config")
+
+		cache, err := NewCache(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		health, err := NewProviderHealth(configPath.GetPath())
+		if err != nil {
+			return err
+		}
+
+		historyProvider := &configuredProvider{cache: cache, health: health}
+		isTerminal := isatty.IsTerminal(os.Stdout.Fd())
+
+		return runHistory(cmd, args, configPath, historyProvider, &FileConfigProvider{}, isTerminal, time.Now)
+	},
+}
+
+var stationCmd = &cobra.Command{
+	Use:   "station",
+	Short: "Receive the uploads of a personal weather station",
+}
+
+var stationListenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Accept Ecowitt and Weather Underground station uploads on a local port",
+	Long: `Accept Ecowitt and Weather Underground station uploads on a local port.
+
+Point the custom upload server of your station at this host and port. The latest readings
+are stored in station.json next to the cache and served by the 'station' provider.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, err := NewConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath.Custom, _ = cmd.Flags().GetString("config")
+		address, _ := cmd.Flags().GetString("listen")
+
+		store := NewStationStore(filepath.Dir(configPath.GetPath()))
+		log.Printf("Listening for weather station uploads on %s", address)
+		return http.ListenAndServe(address, store)
+	},
+}
+
 func init() {
-	rootCmd.Flags().StringP("config",         "c", "",      "Provide a custom config")
+	rootCmd.PersistentFlags().StringP("config", "c", "", "Provide a custom config")
+
 	rootCmd.Flags().StringP("output",         "o", "table", "Output format (json, table)")
-	rootCmd.Flags().IntP(   "forecast-hours", "n", 23,      "Number of forecast hours to display (1-23). 0 means no hourly forecast.")
+	rootCmd.Flags().StringP("provider",       "p", "",      "Weather provider, see 'wayther providers'. A comma separated list is tried in order.")
+	rootCmd.Flags().IntP(   "forecast-hours", "n", 23,      "Number of forecast hours to display, up to the provider's limit. 0 means no hourly forecast.")
+	rootCmd.Flags().IntP(   "forecast-days",  "d", 0,       "Number of forecast days to display, up to the provider's limit. 0 means no daily forecast.")
+	rootCmd.Flags().StringP("lang",           "",  "",      "Language of the condition texts and labels, e.g. de or fr")
+	rootCmd.Flags().StringP("locale",         "",  "",      "Locale of the times, dates and decimal separators, e.g. en_US or de_DE")
+	rootCmd.Flags().StringP("time-format",    "",  "",      "Clock of the forecast: 12h, 24h or a Go time layout")
+	rootCmd.Flags().BoolP(  "local-time",     "",  false,   "Show the times in the time zone of this machine instead of the location's")
+	rootCmd.Flags().StringP("units",          "u", "",      "Units: metric, imperial, uk-hybrid, optionally with overrides such as 'metric,wind=mph'")
+	rootCmd.Flags().BoolP(  "aqi",            "a", false,   "Fetch the air quality, where the provider supports it")
+	rootCmd.Flags().BoolP(  "alerts",         "",  false,   "Fetch the severe weather alerts, where the provider supports it")
+	rootCmd.Flags().BoolP(  "marine",         "",  false,   "Fetch the marine forecast and the tides, where the provider supports it")
+	rootCmd.Flags().BoolP(  "compare",        "",  false,   "Compare the weather of the locations given as arguments side by side")
 	rootCmd.Flags().BoolP(  "no-cache",       "f", false,   "Force a refresh of the data from the API")
 	rootCmd.Flags().BoolP(  "clean-cache",    "C", false,   "Clean cache entries older than 1h")
+	rootCmd.Flags().StringP("record",         "",  "",      "Record the raw API responses of this run to a directory")
+	rootCmd.Flags().StringP("replay",         "",  "",      "Replay the API responses recorded in a directory")
+
+	alertsCmd.Flags().StringP("provider", "p", "", "Weather provider, see 'wayther providers'. A comma separated list is tried in order.")
+	alertsCmd.Flags().StringP("lang", "", "", "Language of the alerts and labels, e.g. de or fr")
+	alertsCmd.Flags().BoolP("no-cache", "f", false, "Force a refresh of the data from the API")
+
+	searchCmd.Flags().StringP("output", "o", "table",  "Output format (json, table)")
+	searchCmd.Flags().IntP(   "save",   "s", 0,        "Save the location with this number as the location in the config")
+	searchCmd.Flags().StringP("by",     "",  "coords", "Save the location by its coordinates (coords) or its weatherapi.com id (id)")
+
+	historyCmd.Flags().StringP("date",           "",  "",      "Day to show, as YYYY-MM-DD")
+	historyCmd.Flags().StringP("output",         "o", "table", "Output format (json, table)")
+	historyCmd.Flags().StringP("provider",       "p", "",      "Weather provider, see 'wayther providers'. A comma separated list is tried in order.")
+	historyCmd.Flags().IntP(   "forecast-hours", "n", 24,      "Number of hours of the day to display. 0 means no hourly weather.")
+	historyCmd.Flags().StringP("lang",           "",  "",      "Language of the condition texts and labels, e.g. de or fr")
+	historyCmd.Flags().StringP("units",          "u", "",      "Units: metric, imperial, uk-hybrid, optionally with overrides such as 'metric,wind=mph'")
+	historyCmd.Flags().BoolP(  "no-cache",       "f", false,   "Force a refresh of the data from the API")
+	historyCmd.MarkFlagRequired("date")
+
+	stationListenCmd.Flags().StringP("listen", "l", ":8080", "Address to listen on for station uploads")
+
+	rootCmd.AddCommand(providersCmd)
+	rootCmd.AddCommand(alertsCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(searchCmd)
+	stationCmd.AddCommand(stationListenCmd)
+	rootCmd.AddCommand(stationCmd)
 }
 
 // runApp is the main application logic.
@@ -63,6 +238,34 @@ func runApp(cmd *cobra.Command, args []string, configPath ConfigPath, weatherPro
 	}
 
 	config.ParseCommand(cmd, args, isTerminal)
+	if err := checkProviderCapabilities(cmd, config); err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+
+	// Recording and replaying swap the transport of the shared HTTP client, which the concurrent
+	// requests of a comparison would race on, and a capture holds a single location anyway
+	if config.CompareLocations != nil && (config.RecordDir != "" || slices.Contains(config.ProviderChain(), "replay")) {
+		return handleExitError(config, fmt.Errorf("--compare cannot be combined with --record or --replay"), isTerminal)
+	}
+
+	if clock, replaying, err := replayClock(config); err != nil {
+		return handleExitError(config, err, isTerminal)
+	} else if replaying {
+		nowFunc = clock
+	}
+
+	if config.RecordDir != "" {
+		stopRecording, err := startRecording(config, nowFunc())
+		if err != nil {
+			return handleExitError(config, err, isTerminal)
+		}
+		defer stopRecording()
+	}
+
+	if config.CompareLocations != nil {
+		return runComparison(weatherProvider, config, isTerminal, nowFunc)
+	}
+
 	weather, err := NewWeather(weatherProvider, config)
 	if err != nil {
 		return handleExitError(config, err, isTerminal) 
@@ -78,12 +281,159 @@ func runApp(cmd *cobra.Command, args []string, configPath ConfigPath, weatherPro
 	return nil
 }
 
+// runComparison prints the weather of the locations to compare side by side.
+// The comparison is always a table, there is no JSON output for it.
+func runComparison(weatherProvider WeatherProvider, config *Config, isTerminal bool, nowFunc func() time.Time) error {
+
+	if len(config.CompareLocations) < 2 {
+		return handleExitError(config, fmt.Errorf("--compare needs at least two locations"), isTerminal)
+	}
+
+	weathers, err := NewWeatherComparison(weatherProvider, config)
+	if err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+
+	output, err := FormatComparison(weathers, config, nowFunc)
+	if err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+	fmt.Println(output)
+
+	return nil
+}
+
+// runAlerts prints the full descriptions of the active weather alerts for the configured location.
+func runAlerts(cmd *cobra.Command, args []string, configPath ConfigPath, weatherProvider WeatherProvider, configProvider ConfigProvider, nowFunc func() time.Time) error {
+
+	config, err := configProvider.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	config.ParseCommand(cmd, args, true)
+	config.Alerts = true
+	if err := checkProviderCapabilities(cmd, config); err != nil {
+		return err
+	}
+	// Only the providers with alerts may answer, so that "no alerts" is never reported by one that cannot know
+	config.Providers = capableProviders(config, CapabilityAlerts)
+	config.Provider = config.Providers[0]
+
+	weather, err := NewWeather(weatherProvider, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(FormatAlerts(weather, config, nowFunc))
+	return nil
+}
+
+// runSearch lists the locations matching the query, and saves the chosen one into the config file.
+func runSearch(cmd *cobra.Command, args []string, configPath ConfigPath, searcher LocationSearcher, configProvider ConfigProvider) error {
+
+	config, err := configProvider.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	query := strings.Join(args, " ")
+	candidates, err := searcher.SearchLocations(config, query)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no locations found for %q", query)
+	}
+
+	save, _ := cmd.Flags().GetInt("save")
+	if save == 0 {
+		if output, _ := cmd.Flags().GetString("output"); output == "json" {
+			jsonOutput, err := json.Marshal(candidates)
+			if err != nil {
+				return fmt.Errorf("error marshalling JSON output: %w", err)
+			}
+			fmt.Println(string(jsonOutput))
+			return nil
+		}
+		fmt.Println(FormatLocations(candidates))
+		return nil
+	}
+
+	if save < 1 || save > len(candidates) {
+		return fmt.Errorf("no location number %d, expected 1 to %d", save, len(candidates))
+	}
+	by, _ := cmd.Flags().GetString("by")
+	candidate := candidates[save-1]
+	location, err := candidate.Query(by)
+	if err != nil {
+		return err
+	}
+	key, err := SaveLocation(configPath.GetPath(), location)
+	if err != nil {
+		return fmt.Errorf("failed to save the location: %w", err)
+	}
+	fmt.Printf("Saved %s, %s, %s as the %s (%s) in %s\n", candidate.Name, candidate.Region, candidate.Country, key, location, configPath.GetPath())
+	return nil
+}
+
+// runHistory prints the weather of the day given by the date flag for the configured location.
+func runHistory(cmd *cobra.Command, args []string, configPath ConfigPath, historyProvider HistoryProvider, configProvider ConfigProvider, isTerminal bool, nowFunc func() time.Time) error {
+
+	config, err := configProvider.LoadConfig(configPath)
+	if err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+
+	config.ParseCommand(cmd, args, isTerminal)
+	config.ForecastDays = 1
+
+	date, _ := cmd.Flags().GetString("date")
+	day, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return handleExitError(config, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date), isTerminal)
+	}
+	// Compare the calendar dates, as the day starts at local midnight rather than at midnight UTC
+	if day.Format("2006-01-02") > nowFunc().In(time.Local).Format("2006-01-02") {
+		return handleExitError(config, fmt.Errorf("the date %s is in the future, see the forecast instead", date), isTerminal)
+	}
+
+	weather, err := historyProvider.GetHistory(config, date, nowFunc)
+	if err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+
+	// Render the day from its first hour, in the time zone of the location
+	startOfDay := func() time.Time {
+		start, _ := time.ParseInLocation("2006-01-02", date, weather.TimeZone())
+		return start
+	}
+	output, err := FormatOutput(weather, config, startOfDay)
+	if err != nil {
+		return handleExitError(config, err, isTerminal)
+	}
+	fmt.Println(output)
+
+	return nil
+}
+
 // handleExitError provides a centralized way to handle errors and exit the application.
 // It considers whether the output is to a terminal or if JSON output is requested.
 func handleExitError(config *Config, err error, isTerminal bool) error {
 
 	if (config == nil && !isTerminal) || (config != nil && config.Output == "json") {
-		fmt.Printf("{\"text\":\"N/A ☢\",\"tooltip\":\" error fetching weather: %s \"}", err)
+		lang := ""
+		if config != nil {
+			lang = config.Lang
+		}
+		jsonOutput, _ := json.Marshal(struct {
+			Text    string `json:"text"`
+			Tooltip string `json:"tooltip"`
+		}{
+			Text:    "N/A ☢",
+			Tooltip: " " + translate(lang, "error fetching weather: %s", err) + " ",
+		})
+		fmt.Print(string(jsonOutput))
 		return nil
 	}
 
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,36 +37,19 @@ func loadMockResponse(t *testing.T) *WeatherAPIResponse {
 type MockWeatherProvider struct {
 	mockResponse *WeatherAPIResponse
 	err          error
+	historyDate  string
 }
 
-func (m *MockWeatherProvider) GetWeather(config *Config) (*WeatherAPIResponse, error) {
-	return m.mockResponse, m.err
-}
-
-func (m *MockWeatherProvider) ToWeather(w *WeatherAPIResponse) *Weather {
-	var hourlyForecasts []HourlyForecast
-	if len(w.Forecast.Forecastday) > 0 {
-		for _, forecastday := range w.Forecast.Forecastday {
-			for _, hour := range forecastday.Hour {
-				hourlyForecasts = append(hourlyForecasts, HourlyForecast{
-					TimeEpoch:  hour.TimeEpoch,
-					Emoji:      hour.Condition.Emoji,
-					TempC:      hour.TempC,
-					FeelslikeC: hour.FeelslikeC,
-				})
-			}
-		}
+func (m *MockWeatherProvider) GetWeather(config *Config) (*Weather, error) {
+	if m.err != nil {
+		return nil, m.err
 	}
+	return m.mockResponse.toWeather(), nil
+}
 
-	return &Weather{
-		Current: WeatherCurrent{
-			Location: w.Location.Name,
-			Country:  w.Location.Country,
-			Emoji:    w.Current.Condition.Emoji,
-			TempC:    w.Current.TempC,
-		},
-		HourlyForecast: hourlyForecasts,
-	}
+func (m *MockWeatherProvider) GetHistory(config *Config, date string, nowFunc func() time.Time) (*Weather, error) {
+	m.historyDate = date
+	return m.GetWeather(config)
 }
 
 func (m *MockWeatherProvider) CleanCache(maxAge time.Duration) {
@@ -115,11 +102,303 @@ func TestAppOutput(t *testing.T) {
 
 		// The app produces valid JSON, so we check for key substrings.
 		assert.Contains(t, actualOutput, "\"text\":", "Output should contain the JSON key 'text'")
-		assert.Contains(t, actualOutput, "\"text\":\" 1.3°\"", "JSON output should contain the current temperature in the text field")
+		assert.Contains(t, actualOutput, "\"text\":\""+getEmojiForWeatherCode(1003)+" 1.3°\"", "JSON output should contain the current temperature in the text field")
 		assert.Contains(t, actualOutput, "\"tooltip\":", "Output should contain the JSON key 'tooltip'")
 
 	})
 
+	t.Run("JSON Output with fallback chain", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.Providers = []string{"weatherapi", "openmeteo"}
+
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Source: weatherapi", "Tooltip should report which provider answered")
+	})
+
+	t.Run("JSON Output with detailed templates", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ForecastHours = 1
+		config.ShortTmpl = "{{.TempC}}° 💧{{.Humidity}}% 🌬 {{.WindKph}}km/h {{.WindDir}} ({{.TempF}}°F)"
+		config.ForecastTmpl = "{{.Condition}} {{.ChanceOfRain}}% {{.WindDegree}}° {{.GustKph}}km/h {{.VisKm}}km"
+
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"1.3° 💧64% 🌬 9.4km/h NNE (34.3°F)"`)
+		assert.Contains(t, output, "20:00: Clear  0% 18° 19.2km/h 10km")
+	})
+
+	t.Run("Daily Forecast", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.ForecastHours = 0
+		config.ForecastDays = 3
+		config.DailyTmpl = "{{.Emoji}} {{.MintempC}}° - {{.MaxtempC}}°"
+
+		config.Output = "table"
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Daily Forecast:", "Table should have a 'Daily Forecast' section")
+		assert.Contains(t, output, "Sun 12 : "+getEmojiForWeatherCode(1003)+" -1.8° - 2.3°")
+
+		config.Output = "json"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, ` Daily Forecast: \r Sun 12: `, "Tooltip should have a 'Daily Forecast' section")
+	})
+
+	t.Run("Hourly Forecast beyond 23 hours", func(t *testing.T) {
+		start := time.Date(2025, 1, 12, 20, 0, 0, 0, time.Local)
+		weather := &Weather{}
+		for i := range 72 {
+			weather.HourlyForecast = append(weather.HourlyForecast, HourlyForecast{TimeEpoch: start.Add(time.Duration(i) * time.Hour).Unix()})
+		}
+		config := Config{ForecastHours: 48, CurrentTmpl: "now", ForecastTmpl: "hour"}
+		nowFunc := func() time.Time { return start }
+
+		config.Output = "table"
+		output, err := FormatOutput(weather, &config, nowFunc)
+		assert.NoError(t, err)
+		assert.Equal(t, 48, strings.Count(output, ": hour"), "Table should contain 48 hours")
+		assert.Contains(t, output, "Mon 13", "Table should separate the days")
+		assert.Contains(t, output, "Tue 14", "Table should separate the days")
+		assert.NotContains(t, output, "Wed 15", "Table should stop after 48 hours")
+
+		config.Output = "json"
+		output, err = FormatOutput(weather, &config, nowFunc)
+		assert.NoError(t, err)
+		assert.Equal(t, 48, strings.Count(output, ": hour"), "Tooltip should contain 48 hours")
+	})
+
+	t.Run("Astronomy", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.ForecastHours = 0
+		config.AstroTmpl = "{{.Sunrise}}-{{.Sunset}} {{.MoonEmoji}} {{.MoonIllumination}}%"
+
+		config.Output = "table"
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Astronomy:", "Table should have an 'Astronomy' section")
+		assert.Contains(t, output, "08:28-16:59 🌔 95%")
+
+		// The times follow the clock of the locale and the time format
+		config.TimeFormat = "12h"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "8:28 AM-4:59 PM 🌔 95%")
+		config.TimeFormat = ""
+
+		// The current templates can show the moon at night
+		config.Output = "json"
+		config.ShortTmpl = "{{if .IsDay}}{{.Emoji}}{{else}}{{.MoonEmoji}}{{end}} {{.TempC}}° {{.Sunset}}"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"🌔 1.3° 16:59"`)
+	})
+
+	t.Run("JSON Output with air quality", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ShortTmpl = "{{.TempC}}°{{with .AirQuality}} PM2.5 {{.Pm25}} {{.UsEpaCategory}}{{end}}"
+
+		weather := mockResponse.toWeather()
+		output, err := FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"1.3°"`)
+		assert.NotContains(t, output, `"class"`, "Output should not have a class without air quality data")
+
+		weather.Current.AirQuality = &WeatherAirQuality{Pm25: 40.5, UsEpaIndex: 4}
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"1.3° PM2.5 40.5 Unhealthy"`)
+		assert.Contains(t, output, `"class":"aqi-unhealthy"`)
+	})
+
+	t.Run("Alerts", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ForecastHours = 1
+
+		weather := mockResponse.toWeather()
+		weather.Alerts = []WeatherAlert{
+			{Headline: "Yellow wind warning", Severity: "Moderate", ExpiresEpoch: time.Date(2025, 1, 12, 20, 0, 0, 0, time.UTC).Unix()},
+			{Headline: "Expired fog warning", Severity: "Minor", ExpiresEpoch: time.Date(2025, 1, 12, 9, 0, 0, 0, time.UTC).Unix()},
+		}
+
+		output, err := FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"⚠ `+getEmojiForWeatherCode(1003)+` 1.3°"`, "Text should be marked while an alert is active")
+		assert.Contains(t, output, `"tooltip":" ⚠ Moderate: Yellow wind warning, until Sun 12 21:00 \r 20:00:`, "Alerts should head the tooltip")
+		assert.NotContains(t, output, "Expired fog warning", "Expired alerts should not be shown")
+
+		config.Output = "table"
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Alerts:")
+		assert.Contains(t, output, "⚠ Moderate: Yellow wind warning, until Sun 12 21:00")
+
+		weather.Alerts = weather.Alerts[1:]
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "Alerts:", "Table should have no 'Alerts' section without active alerts")
+	})
+
+	t.Run("Marine", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "table"
+		config.ForecastHours = 1
+		config.MarineTmpl = `{{printf "%.1f" .WaveHeight}}{{.HeightUnit}} {{.SwellDir}} {{printf "%.1f" .WaterTemp}}°`
+
+		weather := mockResponse.toWeather()
+		weather.Marine = &WeatherMarine{
+			Hourly: []MarineHour{
+				{TimeEpoch: time.Date(2025, 1, 12, 18, 0, 0, 0, time.UTC).Unix(), WaveHeightM: 0.9, SwellDir: "W", WaterTempC: 8.1},
+				{TimeEpoch: time.Date(2025, 1, 12, 19, 0, 0, 0, time.UTC).Unix(), WaveHeightM: 1.2, SwellDir: "WSW", WaterTempC: 8.0},
+			},
+			Tides: []WeatherTide{
+				{TimeEpoch: time.Date(2025, 1, 12, 14, 5, 0, 0, time.UTC).Unix(), High: true, HeightM: 4.1},
+				{TimeEpoch: time.Date(2025, 1, 12, 20, 17, 0, 0, time.UTC).Unix(), HeightM: 0.6},
+				{TimeEpoch: time.Date(2025, 1, 13, 2, 31, 0, 0, time.UTC).Unix(), High: true, HeightM: 4.3},
+			},
+		}
+
+		output, err := FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Marine:")
+		assert.Contains(t, output, "20:00 : 1.2m WSW 8.0°", "Marine section should show the sea conditions of the next hour")
+		assert.NotContains(t, output, "0.9m", "Past hours should not be shown")
+		assert.Contains(t, output, "▼ Low tide 21:17, 0.6m")
+		assert.Contains(t, output, "▲ High tide 03:31, 4.3m")
+		assert.NotContains(t, output, "14:05", "Past tides should not be shown")
+
+		config.Output = "json"
+		config.Units = "imperial"
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, ` ▼ Low tide 21:17, 2.0ft \r ▲ High tide 03:31, 14.1ft `, "Tides should follow the hourly forecast in the tooltip")
+
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.NotContains(t, output, "tide", "Tides should only be shown with the marine forecast")
+	})
+
+	t.Run("Imperial Units", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ForecastHours = 1
+		config.ForecastDays = 1
+		config.Units = "imperial"
+		config.ShortTmpl = "{{.Temp}}{{.TempUnit}} {{.Wind}}{{.WindUnit}}"
+		config.ForecastTmpl = "{{.Temp}}{{.TempUnit}} {{.Vis}}{{.VisUnit}}"
+		config.DailyTmpl = "{{.MinTemp}} - {{.MaxTemp}}{{.TempUnit}}"
+
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"34.3°F 5.8mph"`)
+		assert.Contains(t, output, "20:00: 29.8°F 6.2mi")
+		assert.Contains(t, output, "Sun 12: 28.8 - 36.1°F")
+
+		config.Units = "metric,wind=kn"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"1.3°C 5.1kn"`)
+
+		config.Units = "kelvin"
+		_, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.ErrorContains(t, err, "unknown unit system")
+	})
+
+	t.Run("Translated labels", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "table"
+		config.ForecastHours = 1
+		config.ForecastDays = 1
+		config.Lang = "de"
+
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, "Aktuell:")
+		assert.Contains(t, output, "Stündliche Vorhersage:")
+		assert.Contains(t, output, "Tägliche Vorhersage:")
+		assert.NotContains(t, output, "Current:")
+
+		config.Output = "json"
+		config.Providers = []string{"weatherapi", "openmeteo"}
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, " Tägliche Vorhersage: ")
+		assert.Contains(t, output, " Quelle: weatherapi ")
+	})
+
+	t.Run("Locale", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ForecastHours = 1
+		config.ForecastDays = 1
+		config.ShortTmpl = `{{printf "%.1f" .Temp}}°`
+		config.ForecastTmpl = `{{printf "%.1f" .Temp}}°`
+		config.DailyTmpl = `{{printf "%.1f" .MaxTemp}}°`
+		config.Locale = "en_US"
+
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, " 8:00 PM: -1.2° ")
+		assert.Contains(t, output, " Sun 1/12: 2.3° ")
+
+		config.Locale = "de_DE"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"1,3°"`)
+		assert.Contains(t, output, " 20:00: -1,2° ")
+		assert.Contains(t, output, " So 12.01.: 2,3° ", "The weekday should be in the language of the locale")
+
+		config.Locale = "fr_BE"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, " dim. 12/01: 2,3° ")
+
+		config.Output = "table"
+		config.TimeFormat = "12h"
+		output, err = FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, " 8:00 PM : -1,2°")
+	})
+
+	t.Run("Location time zone", func(t *testing.T) {
+		config := *configProvider.mockConfig
+		config.Output = "json"
+		config.ForecastHours = 1
+		config.ForecastDays = 1
+		config.ShortTmpl = `{{.TzID}} {{.LocationTime.Format "15:04"}} ({{.LocalTime.Format "15:04"}})`
+		config.ForecastTmpl = `{{.LocalTime.Format "15:04"}}`
+		config.DailyTmpl = `{{.Date}}`
+
+		// Pin the machine to New York, so it differs from every location's zone
+		defer func(local *time.Location) { time.Local = local }(time.Local)
+		time.Local, _ = time.LoadLocation("America/New_York")
+
+		// 18:10 UTC is 19:10 in Brussels and 13:10 in New York
+		output, err := FormatOutput(mockResponse.toWeather(), &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"Europe/Brussels 19:10 (13:10)"`)
+		assert.Contains(t, output, " 20:00: 14:00 ", "Hours should be shown in the location's time zone")
+
+		// 18:10 UTC is 03:10 of the next day in Tokyo
+		weather := mockResponse.toWeather()
+		weather.Location.TzID = "Asia/Tokyo"
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, `"text":"Asia/Tokyo 03:10 (13:10)"`)
+		assert.Contains(t, output, " 04:00: 14:00 ", "Hours should be shown in the location's time zone")
+		assert.NotContains(t, output, "2025-01-12", "The daily forecast should start at the location's today")
+
+		config.LocalTime = true
+		output, err = FormatOutput(weather, &config, mockNowFunc)
+		assert.NoError(t, err)
+		assert.Contains(t, output, " 14:00: 14:00 ", "Hours should be shown in the machine's time zone")
+	})
+
 	t.Run("Table Output", func(t *testing.T) {
 		configProvider.mockConfig.ForecastHours = 4
 		// Redirect stdout
@@ -149,6 +428,325 @@ func TestAppOutput(t *testing.T) {
 	})
 }
 
+// MockComparisonProvider records the locations it is asked for, concurrently, and names the weather after them.
+type MockComparisonProvider struct {
+	MockWeatherProvider
+	mu        sync.Mutex
+	locations []string
+}
+
+func (m *MockComparisonProvider) GetWeather(config *Config) (*Weather, error) {
+	m.mu.Lock()
+	m.locations = append(m.locations, config.Location)
+	m.mu.Unlock()
+
+	weather := m.mockResponse.toWeather()
+	weather.Current.Location = config.Location
+	return weather, nil
+}
+
+func TestRunAppCompare(t *testing.T) {
+	// The rows follow the hours of the machine's time zone, pin it
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.UTC
+
+	mockResponse := loadMockResponse(t)
+	weatherProvider := &MockComparisonProvider{MockWeatherProvider: MockWeatherProvider{mockResponse: mockResponse}}
+	configProvider := &MockConfigProvider{mockConfig: &Config{
+		Location:     "Brussels",
+		Locations:    map[string]string{"home": "50.8467,4.3525"},
+		CurrentTmpl:  "{{.Location}}",
+		ForecastTmpl: "{{.TempC}}°",
+	}}
+	mockNowFunc := func() time.Time {
+		return time.Unix(mockResponse.Location.LocaltimeEpoch, 0)
+	}
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("compare", true, "")
+	cmd.Flags().Int("forecast-hours", 2, "")
+
+	// Redirect stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runApp(cmd, []string{"home", "New York"}, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.NoError(t, err)
+
+	// Restore stdout and read the captured output
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	actualOutput := buf.String()
+
+	assert.ElementsMatch(t, []string{"50.8467,4.3525", "New York"}, weatherProvider.locations, "Saved locations should be resolved")
+	assert.Regexp(t, `│ +│ home +│ New York +│`, actualOutput, "Each location should have a column")
+	assert.Regexp(t, `│ Current: +│ 50\.8467,4\.3525 +│ New York +│`, actualOutput)
+	assert.Regexp(t, `│ 19:00 +│ -1\.2° +│ -1\.2° +│`, actualOutput, "Hourly rows should be aligned")
+	assert.Regexp(t, `│ 20:00 +│`, actualOutput)
+	assert.NotContains(t, actualOutput, "21:00")
+
+	// The rows start on a full hour of a time zone that is not a whole number of hours from UTC
+	time.Local, _ = time.LoadLocation("Asia/Kolkata")
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+	err = runApp(cmd, []string{"home", "New York"}, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.NoError(t, err)
+	w.Close()
+	os.Stdout = oldStdout
+	buf.Reset()
+	io.Copy(&buf, r)
+	actualOutput = buf.String()
+
+	assert.Regexp(t, `│ 00:00 +│ -1\.2° +│ -1\.2° +│`, actualOutput, "Hourly rows should start on a full local hour")
+	assert.Regexp(t, `│ 01:00 +│`, actualOutput)
+	assert.NotContains(t, actualOutput, ":30")
+
+	err = runApp(cmd, []string{"home"}, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.EqualError(t, err, "--compare needs at least two locations")
+
+	// Recordings and replays cover a single location
+	for _, flag := range []string{"record", "replay"} {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("compare", true, "")
+		cmd.Flags().String(flag, "", "")
+		cmd.Flags().Set(flag, t.TempDir())
+		err = runApp(cmd, []string{"home", "New York"}, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+		assert.EqualError(t, err, "--compare cannot be combined with --record or --replay", flag)
+	}
+}
+
+func TestRunAlerts(t *testing.T) {
+	mockResponse := loadMockResponse(t)
+	mockResponse.Alerts = Alerts{Alert: []Alert{{
+		Headline:    "Flood Warning issued January 12 at 9:00AM",
+		Severity:    "Severe",
+		Event:       "Flood Warning",
+		Areas:       "Brussels",
+		Effective:   "2025-01-12T09:00:00+00:00",
+		Expires:     "2025-01-13T09:00:00+00:00",
+		Desc:        "The river is expected to rise above flood stage.",
+		Instruction: "Do not drive through flooded roads.",
+	}}}
+	mockNowFunc := func() time.Time {
+		return time.Unix(mockResponse.Location.LocaltimeEpoch, 0)
+	}
+
+	// Redirect stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	configProvider := &MockConfigProvider{mockConfig: &Config{Location: "Brussels"}}
+	err := runAlerts(&cobra.Command{}, nil, ConfigPath{}, &MockWeatherProvider{mockResponse: mockResponse}, configProvider, mockNowFunc)
+	assert.NoError(t, err)
+	assert.True(t, configProvider.mockConfig.Alerts, "The alerts should be requested from the provider")
+
+	// Restore stdout and read the captured output
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	actualOutput := buf.String()
+
+	assert.Contains(t, actualOutput, "⚠ Severe: Flood Warning issued January 12 at 9:00AM, until Mon 13 10:00")
+	assert.Contains(t, actualOutput, "Event: Flood Warning")
+	assert.Contains(t, actualOutput, "Areas: Brussels")
+	assert.Contains(t, actualOutput, "The river is expected to rise above flood stage.")
+	assert.Contains(t, actualOutput, "Do not drive through flooded roads.")
+
+	// Providers without alerts are rejected, unless the chain has one with alerts
+	configProvider.mockConfig.Provider = "openmeteo"
+	configProvider.mockConfig.Providers = nil
+	err = runAlerts(&cobra.Command{}, nil, ConfigPath{}, &MockWeatherProvider{mockResponse: mockResponse}, configProvider, mockNowFunc)
+	assert.EqualError(t, err, `weather provider "openmeteo" does not support alerts data (--alerts)`)
+
+	// Only the providers with alerts of the chain are asked, even when an earlier one would answer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/forecast.json":
+			json.NewEncoder(w).Encode(mockResponse)
+		default:
+			t.Errorf("Unexpected request to: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	originalURL, originalOpenMeteoURL := weatherAPIURL, openMeteoURL
+	weatherAPIURL = server.URL + "/v1/forecast.json"
+	openMeteoURL = server.URL + "/v1/forecast"
+	defer func() { weatherAPIURL, openMeteoURL = originalURL, originalOpenMeteoURL }()
+
+	configProvider.mockConfig.Providers = []string{"openmeteo", "weatherapi"}
+	configProvider.mockConfig.APIKey = "test"
+	configProvider.mockConfig.NoCache = true
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+	err = runAlerts(&cobra.Command{}, nil, ConfigPath{}, &configuredProvider{cache: newMemoryCache()}, configProvider, mockNowFunc)
+	w.Close()
+	os.Stdout = oldStdout
+	assert.NoError(t, err)
+	buf.Reset()
+	io.Copy(&buf, r)
+	assert.Contains(t, buf.String(), "Flood Warning")
+}
+
+type MockLocationSearcher struct {
+	candidates []LocationCandidate
+}
+
+func (m *MockLocationSearcher) SearchLocations(config *Config, query string) ([]LocationCandidate, error) {
+	return m.candidates, nil
+}
+
+func TestRunSearch(t *testing.T) {
+	searcher := &MockLocationSearcher{candidates: []LocationCandidate{
+		{ID: 2629833, Name: "Springfield", Region: "Illinois", Country: "United States of America", Lat: 39.8, Lon: -89.64},
+		{ID: 2630125, Name: "Springfield", Region: "Massachusetts", Country: "United States of America", Lat: 42.1, Lon: -72.59},
+	}}
+	configProvider := &MockConfigProvider{mockConfig: &Config{APIKey: "mock-key"}}
+	configPath := ConfigPath{Custom: filepath.Join(t.TempDir(), "config.json")}
+	if err := WriteConfig(&Config{Location: "Springfield", Output: "json"}, configPath.GetPath()); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	newSearchCmd := func(save int, by string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("output", "table", "")
+		cmd.Flags().Int("save", save, "")
+		cmd.Flags().String("by", by, "")
+		return cmd
+	}
+
+	// Redirect stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runSearch(newSearchCmd(0, "coords"), []string{"Springfield"}, configPath, searcher, configProvider)
+	assert.NoError(t, err)
+
+	// Restore stdout and read the captured output
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	actualOutput := buf.String()
+
+	assert.Contains(t, actualOutput, "Massachusetts")
+	assert.Contains(t, actualOutput, "39.8000, -89.6400")
+
+	// Saving the second one, by coordinates and then by id, keeps the other settings
+	assert.NoError(t, runSearch(newSearchCmd(2, "coords"), []string{"Springfield"}, configPath, searcher, configProvider))
+	config, err := LoadConfigFromFile(configPath.GetPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "42.1000,-72.5900", config.Location)
+	assert.Equal(t, "json", config.Output)
+
+	assert.NoError(t, runSearch(newSearchCmd(2, "id"), []string{"Springfield"}, configPath, searcher, configProvider))
+	config, err = LoadConfigFromFile(configPath.GetPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "id:2630125", config.Location)
+
+	// A default location takes precedence over the location, so it is the one saved
+	if err := WriteConfig(&Config{Location: "Springfield", DefaultLocation: "home"}, configPath.GetPath()); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	assert.NoError(t, runSearch(newSearchCmd(1, "coords"), []string{"Springfield"}, configPath, searcher, configProvider))
+	config, err = LoadConfigFromFile(configPath.GetPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "39.8000,-89.6400", config.DefaultLocation)
+	config.ParseCommand(&cobra.Command{}, nil, true)
+	assert.Equal(t, "39.8000,-89.6400", config.Location, "The saved location should take effect")
+
+	// A default location naming a saved location is replaced, the saved locations and unknown keys are kept
+	settings := `{"location": "Springfield", "default_location": "home", "locations": {"home": "50.8467,4.3525", "office": "51.2194,4.4025"}, "future_key": 42}`
+	if err := os.WriteFile(configPath.GetPath(), []byte(settings), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	assert.NoError(t, runSearch(newSearchCmd(1, "coords"), []string{"Springfield"}, configPath, searcher, configProvider))
+	config, err = LoadConfigFromFile(configPath.GetPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "39.8000,-89.6400", config.DefaultLocation)
+	assert.Equal(t, map[string]string{"home": "50.8467,4.3525", "office": "51.2194,4.4025"}, config.Locations)
+	assert.Equal(t, "Springfield", config.Location)
+	data, err := os.ReadFile(configPath.GetPath())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"future_key": 42`)
+
+	err = runSearch(newSearchCmd(3, "coords"), []string{"Springfield"}, configPath, searcher, configProvider)
+	assert.EqualError(t, err, "no location number 3, expected 1 to 2")
+
+	err = runSearch(newSearchCmd(1, "name"), []string{"Springfield"}, configPath, searcher, configProvider)
+	assert.EqualError(t, err, `unknown location query "name", expected coords or id`)
+
+	searcher.candidates = nil
+	err = runSearch(newSearchCmd(0, "coords"), []string{"Nowhere", "Town"}, configPath, searcher, configProvider)
+	assert.EqualError(t, err, `no locations found for "Nowhere Town"`)
+}
+
+func TestRunHistory(t *testing.T) {
+	// The day is shown in the location's time zone, whatever the machine's
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local, _ = time.LoadLocation("America/New_York")
+
+	mockResponse := loadMockResponse(t)
+	weatherProvider := &MockWeatherProvider{mockResponse: mockResponse}
+	configProvider := &MockConfigProvider{mockConfig: &Config{Location: "Brussels"}}
+	mockNowFunc := func() time.Time {
+		return time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+	newHistoryCmd := func(date string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("date", date, "")
+		cmd.Flags().Int("forecast-hours", 24, "")
+		cmd.Flags().String("output", "table", "")
+		return cmd
+	}
+
+	// Redirect stdout
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := runHistory(newHistoryCmd("2025-01-12"), nil, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.NoError(t, err)
+
+	// Restore stdout and read the captured output
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	actualOutput := buf.String()
+
+	assert.Equal(t, "2025-01-12", weatherProvider.historyDate)
+	assert.Equal(t, 1, configProvider.mockConfig.ForecastDays, "The summary of the day should be shown")
+	assert.Contains(t, actualOutput, "00:00 :", "The day should be shown from midnight")
+	assert.Contains(t, actualOutput, "23:00 :", "The day should be shown up to its last hour")
+	assert.Contains(t, actualOutput, "Daily Forecast:")
+
+	err = runHistory(newHistoryCmd("12/01/2025"), nil, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.EqualError(t, err, `invalid date "12/01/2025", expected YYYY-MM-DD`)
+
+	err = runHistory(newHistoryCmd("2025-03-02"), nil, ConfigPath{}, weatherProvider, configProvider, true, mockNowFunc)
+	assert.EqualError(t, err, "the date 2025-03-02 is in the future, see the forecast instead")
+
+	// Just after midnight ahead of UTC, the UTC date is still the day before but today is not in the future
+	time.Local, _ = time.LoadLocation("Asia/Tokyo")
+	afterMidnight := func() time.Time { return time.Date(2025, 3, 2, 0, 30, 0, 0, time.Local) }
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+	err = runHistory(newHistoryCmd("2025-03-02"), nil, ConfigPath{}, weatherProvider, configProvider, true, afterMidnight)
+	w.Close()
+	os.Stdout = oldStdout
+	io.Copy(io.Discard, r)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-03-02", weatherProvider.historyDate)
+
+	err = runHistory(newHistoryCmd("2025-03-03"), nil, ConfigPath{}, weatherProvider, configProvider, true, afterMidnight)
+	assert.EqualError(t, err, "the date 2025-03-03 is in the future, see the forecast instead")
+}
+
 func TestExecutionError(t *testing.T) {
 	t.Run("Weather Provider Error - Terminal Output", func(t *testing.T) {
 		weatherProvider := &MockWeatherProvider{err: errors.New("mock weather error")}
@@ -181,6 +779,28 @@ func TestExecutionError(t *testing.T) {
 		assert.Contains(t, actualOutput, `{"text":"N/A ☢","tooltip":" error fetching weather: mock weather error "}`)
 	})
 
+	t.Run("Weather Provider Error - Translated JSON Output", func(t *testing.T) {
+		// Redirect stdout
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		weatherProvider := &MockWeatherProvider{err: errors.New("mock weather error")}
+		configProvider := &MockConfigProvider{mockConfig: &Config{Output: "json", Lang: "el"}}
+
+		err := runApp(&cobra.Command{}, []string{"some-location"}, ConfigPath{}, weatherProvider, configProvider, false, time.Now)
+		assert.NoError(t, err)
+
+		// Restore stdout and read the captured output
+		w.Close()
+		os.Stdout = oldStdout
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		actualOutput := buf.String()
+
+		assert.Contains(t, actualOutput, `"tooltip":" σφάλμα λήψης καιρού: mock weather error "`)
+	})
+
 	t.Run("Config Load Error - Terminal Output", func(t *testing.T) {
 		weatherProvider := &MockWeatherProvider{}
 		configProvider := &MockConfigProvider{err: errors.New("mock config load error"), mockConfig: &Config{Output: "table"}} // Simulate terminal output
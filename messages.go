@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// messageCatalog holds the translations of the fixed strings of the output, keyed by language
+// and by the English message. Messages missing from a language are shown in English.
+var messageCatalog = map[string]map[string]string{
+	"de": {
+		"Current:":                         "Aktuell:",
+		"Hourly Forecast:":                 "Stündliche Vorhersage:",
+		"Daily Forecast:":                  "Tägliche Vorhersage:",
+		"Astronomy:":                       "Astronomie:",
+		"Alerts:":                          "Warnungen:",
+		"Marine:":                          "Seewetter:",
+		"High tide %s, %s":                 "Hochwasser %s, %s",
+		"Low tide %s, %s":                  "Niedrigwasser %s, %s",
+		"Source: %s":                       "Quelle: %s",
+		"until %s":                         "bis %s",
+		"Event: %s":                        "Ereignis: %s",
+		"Urgency: %s":                      "Dringlichkeit: %s",
+		"Areas: %s":                        "Gebiete: %s",
+		"Effective: %s":                    "Gültig ab: %s",
+		"Expires: %s":                      "Gültig bis: %s",
+		"No active weather alerts for %s.": "Keine aktiven Wetterwarnungen für %s.",
+		"error fetching weather: %s":       "Fehler beim Abrufen des Wetters: %s",
+		"Mon":                              "Mo",
+		"Tue":                              "Di",
+		"Wed":                              "Mi",
+		"Thu":                              "Do",
+		"Fri":                              "Fr",
+		"Sat":                              "Sa",
+		"Sun":                              "So",
+	},
+	"el": {
+		"Current:":                         "Τώρα:",
+		"Hourly Forecast:":                 "Ωριαία πρόγνωση:",
+		"Daily Forecast:":                  "Ημερήσια πρόγνωση:",
+		"Astronomy:":                       "Αστρονομία:",
+		"Alerts:":                          "Ειδοποιήσεις:",
+		"Marine:":                          "Θαλάσσιος καιρός:",
+		"High tide %s, %s":                 "Πλημμυρίδα %s, %s",
+		"Low tide %s, %s":                  "Άμπωτη %s, %s",
+		"Source: %s":                       "Πηγή: %s",
+		"until %s":                         "έως %s",
+		"Event: %s":                        "Συμβάν: %s",
+		"Urgency: %s":                      "Επείγον: %s",
+		"Areas: %s":                        "Περιοχές: %s",
+		"Effective: %s":                    "Ισχύει από: %s",
+		"Expires: %s":                      "Λήγει: %s",
+		"No active weather alerts for %s.": "Δεν υπάρχουν ενεργές ειδοποιήσεις καιρού για %s.",
+		"error fetching weather: %s":       "σφάλμα λήψης καιρού: %s",
+		"Mon":                              "Δευ",
+		"Tue":                              "Τρί",
+		"Wed":                              "Τετ",
+		"Thu":                              "Πέμ",
+		"Fri":                              "Παρ",
+		"Sat":                              "Σάβ",
+		"Sun":                              "Κυρ",
+	},
+	"es": {
+		"Current:":                         "Actual:",
+		"Hourly Forecast:":                 "Pronóstico por horas:",
+		"Daily Forecast:":                  "Pronóstico diario:",
+		"Astronomy:":                       "Astronomía:",
+		"Alerts:":                          "Alertas:",
+		"Marine:":                          "Tiempo marítimo:",
+		"High tide %s, %s":                 "Pleamar %s, %s",
+		"Low tide %s, %s":                  "Bajamar %s, %s",
+		"Source: %s":                       "Fuente: %s",
+		"until %s":                         "hasta %s",
+		"Event: %s":                        "Evento: %s",
+		"Urgency: %s":                      "Urgencia: %s",
+		"Areas: %s":                        "Zonas: %s",
+		"Effective: %s":                    "Vigente desde: %s",
+		"Expires: %s":                      "Expira: %s",
+		"No active weather alerts for %s.": "No hay alertas meteorológicas activas para %s.",
+		"error fetching weather: %s":       "error al obtener el tiempo: %s",
+		"Mon":                              "lun",
+		"Tue":                              "mar",
+		"Wed":                              "mié",
+		"Thu":                              "jue",
+		"Fri":                              "vie",
+		"Sat":                              "sáb",
+		"Sun":                              "dom",
+	},
+	"fr": {
+		"Current:":                         "Actuellement :",
+		"Hourly Forecast:":                 "Prévisions horaires :",
+		"Daily Forecast:":                  "Prévisions quotidiennes :",
+		"Astronomy:":                       "Astronomie :",
+		"Alerts:":                          "Alertes :",
+		"Marine:":                          "Météo marine :",
+		"High tide %s, %s":                 "Marée haute %s, %s",
+		"Low tide %s, %s":                  "Marée basse %s, %s",
+		"Source: %s":                       "Source : %s",
+		"until %s":                         "jusqu'à %s",
+		"Event: %s":                        "Événement : %s",
+		"Urgency: %s":                      "Urgence : %s",
+		"Areas: %s":                        "Zones : %s",
+		"Effective: %s":                    "En vigueur : %s",
+		"Expires: %s":                      "Expire : %s",
+		"No active weather alerts for %s.": "Aucune alerte météo en cours pour %s.",
+		"error fetching weather: %s":       "erreur lors de la récupération de la météo : %s",
+		"Mon":                              "lun.",
+		"Tue":                              "mar.",
+		"Wed":                              "mer.",
+		"Thu":                              "jeu.",
+		"Fri":                              "ven.",
+		"Sat":                              "sam.",
+		"Sun":                              "dim.",
+	},
+	"it": {
+		"Current:":                         "Attuale:",
+		"Hourly Forecast:":                 "Previsioni orarie:",
+		"Daily Forecast:":                  "Previsioni giornaliere:",
+		"Astronomy:":                       "Astronomia:",
+		"Alerts:":                          "Allerte:",
+		"Marine:":                          "Meteo marina:",
+		"High tide %s, %s":                 "Alta marea %s, %s",
+		"Low tide %s, %s":                  "Bassa marea %s, %s",
+		"Source: %s":                       "Fonte: %s",
+		"until %s":                         "fino a %s",
+		"Event: %s":                        "Evento: %s",
+		"Urgency: %s":                      "Urgenza: %s",
+		"Areas: %s":                        "Zone: %s",
+		"Effective: %s":                    "In vigore dal: %s",
+		"Expires: %s":                      "Scade: %s",
+		"No active weather alerts for %s.": "Nessuna allerta meteo attiva per %s.",
+		"error fetching weather: %s":       "errore nel recupero del meteo: %s",
+		"Mon":                              "lun",
+		"Tue":                              "mar",
+		"Wed":                              "mer",
+		"Thu":                              "gio",
+		"Fri":                              "ven",
+		"Sat":                              "sab",
+		"Sun":                              "dom",
+	},
+	"nl": {
+		"Current:":                         "Huidig:",
+		"Hourly Forecast:":                 "Verwachting per uur:",
+		"Daily Forecast:":                  "Verwachting per dag:",
+		"Astronomy:":                       "Astronomie:",
+		"Alerts:":                          "Waarschuwingen:",
+		"Marine:":                          "Zeeweer:",
+		"High tide %s, %s":                 "Hoogwater %s, %s",
+		"Low tide %s, %s":                  "Laagwater %s, %s",
+		"Source: %s":                       "Bron: %s",
+		"until %s":                         "tot %s",
+		"Event: %s":                        "Gebeurtenis: %s",
+		"Urgency: %s":                      "Urgentie: %s",
+		"Areas: %s":                        "Gebieden: %s",
+		"Effective: %s":                    "Geldig vanaf: %s",
+		"Expires: %s":                      "Verloopt: %s",
+		"No active weather alerts for %s.": "Geen actieve weerswaarschuwingen voor %s.",
+		"error fetching weather: %s":       "fout bij het ophalen van het weer: %s",
+		"Mon":                              "ma",
+		"Tue":                              "di",
+		"Wed":                              "wo",
+		"Thu":                              "do",
+		"Fri":                              "vr",
+		"Sat":                              "za",
+		"Sun":                              "zo",
+	},
+}
+
+// translate returns the message in the given language, formatted with the arguments.
+// Regional variants such as "pt_BR" or "de-AT" fall back to their base language.
+func translate(lang string, message string, args ...any) string {
+	if messages, ok := catalogFor(lang); ok {
+		if translated, ok := messages[message]; ok {
+			message = translated
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// catalogFor returns the messages of a language, or of its base language.
+func catalogFor(lang string) (map[string]string, bool) {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "-", "_"))
+	if messages, ok := messageCatalog[lang]; ok {
+		return messages, true
+	}
+	base, _, _ := strings.Cut(lang, "_")
+	messages, ok := messageCatalog[base]
+	return messages, ok
+}
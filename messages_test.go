@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Current:", translate("", "Current:"))
+	assert.Equal(t, "Aktuell:", translate("de", "Current:"))
+	assert.Equal(t, "Quelle: metno", translate("de", "Source: %s", "metno"))
+
+	// Regional variants fall back to the base language
+	assert.Equal(t, "Aktuell:", translate("de-AT", "Current:"))
+	assert.Equal(t, "Prévisions horaires :", translate("FR_ca", "Hourly Forecast:"))
+
+	// Unknown languages and messages are shown in English
+	assert.Equal(t, "Source: metno", translate("tlh", "Source: %s", "metno"))
+	assert.Equal(t, "Not translated", translate("de", "Not translated"))
+}
+
+func TestMessageCatalogComplete(t *testing.T) {
+	reference := messageCatalog["de"]
+	for lang, messages := range messageCatalog {
+		for message := range reference {
+			assert.Contains(t, messages, message, "Language %s is missing a translation", lang)
+		}
+	}
+}
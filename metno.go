@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metnoSymbolToConditionMap maps the met.no symbol codes (without the _day/_night/_polartwilight
+// variant suffix) to a description and to the weatherapi.com condition code sharing the same icon.
+var metnoSymbolToConditionMap = map[string]conditionMapping{
+	"clearsky":                     {"Clear sky", 1000},
+	"fair":                         {"Fair", 1003},
+	"partlycloudy":                 {"Partly cloudy", 1003},
+	"cloudy":                       {"Cloudy", 1006},
+	"fog":                          {"Fog", 1135},
+	"lightrainshowers":             {"Light rain showers", 1240},
+	"rainshowers":                  {"Rain showers", 1243},
+	"heavyrainshowers":             {"Heavy rain showers", 1246},
+	"lightrainshowersandthunder":   {"Light rain showers and thunder", 1273},
+	"rainshowersandthunder":        {"Rain showers and thunder", 1273},
+	"heavyrainshowersandthunder":   {"Heavy rain showers and thunder", 1276},
+	"lightsleetshowers":            {"Light sleet showers", 1249},
+	"sleetshowers":                 {"Sleet showers", 1249},
+	"heavysleetshowers":            {"Heavy sleet showers", 1252},
+	"lightssleetshowersandthunder": {"Light sleet showers and thunder", 1279},
+	"sleetshowersandthunder":       {"Sleet showers and thunder", 1279},
+	"heavysleetshowersandthunder":  {"Heavy sleet showers and thunder", 1282},
+	"lightsnowshowers":             {"Light snow showers", 1255},
+	"snowshowers":                  {"Snow showers", 1255},
+	"heavysnowshowers":             {"Heavy snow showers", 1258},
+	"lightssnowshowersandthunder":  {"Light snow showers and thunder", 1279},
+	"snowshowersandthunder":        {"Snow showers and thunder", 1279},
+	"heavysnowshowersandthunder":   {"Heavy snow showers and thunder", 1282},
+	"lightrain":                    {"Light rain", 1183},
+	"rain":                         {"Rain", 1189},
+	"heavyrain":                    {"Heavy rain", 1195},
+	"lightrainandthunder":          {"Light rain and thunder", 1273},
+	"rainandthunder":               {"Rain and thunder", 1273},
+	"heavyrainandthunder":          {"Heavy rain and thunder", 1276},
+	"lightsleet":                   {"Light sleet", 1204},
+	"sleet":                        {"Sleet", 1204},
+	"heavysleet":                   {"Heavy sleet", 1207},
+	"lightsleetandthunder":         {"Light sleet and thunder", 1279},
+	"sleetandthunder":              {"Sleet and thunder", 1279},
+	"heavysleetandthunder":         {"Heavy sleet and thunder", 1282},
+	"lightsnow":                    {"Light snow", 1213},
+	"snow":                         {"Snow", 1219},
+	"heavysnow":                    {"Heavy snow", 1225},
+	"lightsnowandthunder":          {"Light snow and thunder", 1279},
+	"snowandthunder":               {"Snow and thunder", 1279},
+	"heavysnowandthunder":          {"Heavy snow and thunder", 1282},
+}
+
+// metnoCondition returns the condition mapping of a met.no symbol code (e.g. "partlycloudy_night").
+func metnoCondition(symbol string) (conditionMapping, bool) {
+	base, _, _ := strings.Cut(symbol, "_")
+	condition, ok := metnoSymbolToConditionMap[base]
+	return condition, ok
+}
+
+// getEmojiForMetnoSymbol returns the emoji for a given met.no symbol code.
+func getEmojiForMetnoSymbol(symbol string) string {
+	if condition, ok := metnoCondition(symbol); ok {
+		return getEmojiForWeatherCode(condition.Code)
+	}
+	return "❓" // Default emoji for unknown codes
+}
+
+// getTextForMetnoSymbol returns the description of a given met.no symbol code.
+func getTextForMetnoSymbol(symbol string) string {
+	condition, _ := metnoCondition(symbol)
+	return condition.Text
+}
+
+// metnoURL is the URL of the met.no locationforecast compact endpoint.
+var metnoURL = "https://api.met.no/weatherapi/locationforecast/2.0/compact"
+
+// MetnoResponse represents the structure of the met.no locationforecast/2.0 compact response.
+type MetnoResponse struct {
+	Properties struct {
+		Meta struct {
+			UpdatedAt time.Time `json:"updated_at"`
+		} `json:"meta"`
+		Timeseries []MetnoTimestep `json:"timeseries"`
+	} `json:"properties"`
+}
+
+// MetnoTimestep represents the forecast data of a single point in time.
+type MetnoTimestep struct {
+	Time time.Time `json:"time"`
+	Data struct {
+		Instant struct {
+			Details struct {
+				AirPressureAtSeaLevel float64 `json:"air_pressure_at_sea_level"`
+				AirTemperature        float64 `json:"air_temperature"`
+				CloudAreaFraction     float64 `json:"cloud_area_fraction"`
+				RelativeHumidity      float64 `json:"relative_humidity"`
+				WindFromDirection     float64 `json:"wind_from_direction"`
+				WindSpeed             float64 `json:"wind_speed"`
+			} `json:"details"`
+		} `json:"instant"`
+		Next1Hours  *MetnoPeriod `json:"next_1_hours"`
+		Next6Hours  *MetnoPeriod `json:"next_6_hours"`
+		Next12Hours *MetnoPeriod `json:"next_12_hours"`
+	} `json:"data"`
+}
+
+// MetnoPeriod represents the summary of the period following a timestep.
+type MetnoPeriod struct {
+	Summary struct {
+		SymbolCode string `json:"symbol_code"`
+	} `json:"summary"`
+	Details struct {
+		PrecipitationAmount float64 `json:"precipitation_amount"`
+	} `json:"details"`
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "metno",
+		Description: "MET Norway locationforecast API (keyless)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &metnoProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily},
+	})
+}
+
+// metnoProvider is the implementation of WeatherProvider that uses the keyless MET Norway API.
+// It honours the Expires and Last-Modified headers as required by the met.no terms of service.
+type metnoProvider struct {
+	cache *Cache
+}
+
+// GetWeather fetches weather forecast data from api.met.no for the location in the config.
+// Cached data is served until the Expires time sent by the server, after which the forecast
+// is requested again with If-Modified-Since so unchanged data is not downloaded twice.
+func (p *metnoProvider) GetWeather(c *Config) (*Weather, error) {
+	key := cacheKey("metno", c.Location)
+
+	// Check cache first
+	entry, found := p.cache.Get(key)
+	found = found && !c.NoCache && entry.Weather != nil
+	if found && !entry.IsStale(time.Hour) {
+		return entry.Weather, nil
+	}
+
+	var location WeatherLocation
+	if found {
+		location = entry.Weather.Location
+	} else {
+		var err error
+		if location, err = resolveLocation(c.Location); err != nil {
+			return nil, err
+		}
+	}
+
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(location.Lat, 'f', 4, 64))
+	query.Set("lon", strconv.FormatFloat(location.Lon, 'f', 4, 64))
+
+	req, err := newIdentifiedRequest(c, metnoURL+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if found && entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	newEntry := CacheEntry{LastModified: resp.Header.Get("Last-Modified")}
+	if expires, err := http.ParseTime(resp.Header.Get("Expires")); err == nil {
+		newEntry.Expires = expires
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && found:
+		newEntry.Weather = entry.Weather
+		if newEntry.LastModified == "" {
+			newEntry.LastModified = entry.LastModified
+		}
+	case resp.StatusCode == http.StatusOK:
+		var forecast MetnoResponse
+		if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+			return nil, fmt.Errorf("failed to decode API response: %w", err)
+		}
+		newEntry.Weather = forecast.toWeather(location)
+	default:
+		return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	// Save to cache
+	if err := p.cache.SetEntry(key, newEntry); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return newEntry.Weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *metnoProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// toWeather maps the MetnoResponse to the provider-neutral Weather struct.
+// met.no reports all times in UTC, so days are split in the time zone the forecast is rendered in:
+// the location's when it is known, the machine's otherwise.
+func (r *MetnoResponse) toWeather(location WeatherLocation) *Weather {
+	weather := &Weather{
+		Location: location,
+		Source: WeatherSource{
+			Provider:    "metno",
+			Attribution: "Data from The Norwegian Meteorological Institute (MET Norway)",
+			URL:         "https://api.met.no/",
+		},
+	}
+	zone := weather.TimeZone()
+
+	var day *DailyForecast
+	for i, step := range r.Properties.Timeseries {
+		details := step.Data.Instant.Details
+		period := step.Data.Next1Hours
+		if period == nil {
+			period = step.Data.Next6Hours
+		}
+
+		if i == 0 {
+			weather.Location.LocaltimeEpoch = step.Time.Unix()
+			weather.Current = WeatherCurrent{
+				Location:         location.Name,
+				Country:          location.Country,
+				LastUpdatedEpoch: r.Properties.Meta.UpdatedAt.Unix(),
+				TempC:            details.AirTemperature,
+				FeelslikeC:       details.AirTemperature,
+				Humidity:         int(details.RelativeHumidity),
+				WindKph:          msToKph(details.WindSpeed),
+				WindDegree:       int(details.WindFromDirection),
+				WindDir:          windDirection(int(details.WindFromDirection)),
+				PressureMb:       details.AirPressureAtSeaLevel,
+				Cloud:            int(details.CloudAreaFraction),
+			}
+			if period != nil {
+				weather.Current.Emoji = getEmojiForMetnoSymbol(period.Summary.SymbolCode)
+				weather.Current.Condition = getTextForMetnoSymbol(period.Summary.SymbolCode)
+				weather.Current.IsDay = !strings.HasSuffix(period.Summary.SymbolCode, "_night")
+				weather.Current.PrecipMm = period.Details.PrecipitationAmount
+			}
+		}
+
+		// Hourly forecast, as long as met.no provides hourly steps
+		if step.Data.Next1Hours != nil {
+			symbol := step.Data.Next1Hours.Summary.SymbolCode
+			weather.HourlyForecast = append(weather.HourlyForecast, HourlyForecast{
+				TimeEpoch:  step.Time.Unix(),
+				Emoji:      getEmojiForMetnoSymbol(symbol),
+				Condition:  getTextForMetnoSymbol(symbol),
+				IsDay:      !strings.HasSuffix(symbol, "_night"),
+				TempC:      details.AirTemperature,
+				FeelslikeC: details.AirTemperature,
+				Humidity:   int(details.RelativeHumidity),
+				WindKph:    msToKph(details.WindSpeed),
+				WindDir:    windDirection(int(details.WindFromDirection)),
+				PrecipMm:   step.Data.Next1Hours.Details.PrecipitationAmount,
+			})
+		}
+
+		// Daily forecast, aggregated from all the steps of a day
+		localTime := step.Time.In(zone)
+		date := localTime.Format("2006-01-02")
+		if day == nil || day.Date != date {
+			midnight := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, zone)
+			weather.DailyForecast = append(weather.DailyForecast, DailyForecast{
+				DateEpoch: midnight.Unix(),
+				Date:      date,
+				MaxtempC:  details.AirTemperature,
+				MintempC:  details.AirTemperature,
+			})
+			day = &weather.DailyForecast[len(weather.DailyForecast)-1]
+		}
+		day.MaxtempC = max(day.MaxtempC, details.AirTemperature)
+		day.MintempC = min(day.MintempC, details.AirTemperature)
+		day.AvgtempC = (day.MaxtempC + day.MintempC) / 2
+		day.MaxwindKph = max(day.MaxwindKph, msToKph(details.WindSpeed))
+		if step.Data.Next1Hours != nil {
+			day.TotalprecipMm += step.Data.Next1Hours.Details.PrecipitationAmount
+		} else if step.Data.Next6Hours != nil {
+			day.TotalprecipMm += step.Data.Next6Hours.Details.PrecipitationAmount
+		}
+		// The day's icon is the 12 hour summary starting in the morning, or the first one available
+		if next12 := step.Data.Next12Hours; next12 != nil && (day.Emoji == "" || localTime.Hour() == 6) {
+			day.Emoji = getEmojiForMetnoSymbol(next12.Summary.SymbolCode)
+			day.Condition = getTextForMetnoSymbol(next12.Summary.SymbolCode)
+		}
+	}
+
+	return weather
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetnoProvider_GetWeather(t *testing.T) {
+	// The days of a location given by coordinates are split in the machine's time zone
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.UTC
+
+	lastModified := "Sun, 12 Jan 2025 18:05:00 GMT"
+	requests := 0
+
+	// Create a mock HTTP server honouring If-Modified-Since
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("User-Agent") != defaultUserAgent {
+			t.Errorf("Expected User-Agent to be '%s', got: %s", defaultUserAgent, r.Header.Get("User-Agent"))
+		}
+		if r.URL.Query().Get("lat") != "59.9139" || r.URL.Query().Get("lon") != "10.7522" {
+			t.Errorf("Expected coordinates 59.9139,10.7522, got: %s,%s", r.URL.Query().Get("lat"), r.URL.Query().Get("lon"))
+		}
+
+		w.Header().Set("Expires", time.Now().Add(30*time.Minute).UTC().Format(http.TimeFormat))
+		w.Header().Set("Last-Modified", lastModified)
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Write([]byte(`{"properties": {"meta": {"updated_at": "2025-01-12T18:00:00Z"}, "timeseries": [
+			{"time": "2025-01-12T18:00:00Z", "data": {
+				"instant": {"details": {"air_pressure_at_sea_level": 1021.3, "air_temperature": -2.5, "cloud_area_fraction": 12.5, "relative_humidity": 80.1, "wind_from_direction": 180, "wind_speed": 2.5}},
+				"next_1_hours": {"summary": {"symbol_code": "clearsky_night"}, "details": {"precipitation_amount": 0}},
+				"next_12_hours": {"summary": {"symbol_code": "fair_night"}}}},
+			{"time": "2025-01-12T19:00:00Z", "data": {
+				"instant": {"details": {"air_temperature": -3.1, "wind_speed": 3}},
+				"next_1_hours": {"summary": {"symbol_code": "lightsnow"}, "details": {"precipitation_amount": 0.3}}}},
+			{"time": "2025-01-13T00:00:00Z", "data": {
+				"instant": {"details": {"air_temperature": -5.0}},
+				"next_6_hours": {"summary": {"symbol_code": "cloudy"}, "details": {"precipitation_amount": 1.2}}}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	originalURL := metnoURL
+	metnoURL = server.URL
+	defer func() { metnoURL = originalURL }()
+
+	tempDir, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "cache.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider := &metnoProvider{cache: cache}
+	config := &Config{Location: "59.9139,10.7522"}
+	weather, err := provider.GetWeather(config)
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	// Assertions
+	assert.Equal(t, -2.5, weather.Current.TempC)
+	assert.Equal(t, 80, weather.Current.Humidity)
+	assert.Equal(t, 9.0, weather.Current.WindKph)
+	assert.Equal(t, "S", weather.Current.WindDir)
+	assert.Equal(t, "Clear sky", weather.Current.Condition)
+	assert.False(t, weather.Current.IsDay)
+	assert.Len(t, weather.HourlyForecast, 2)
+	assert.Equal(t, getEmojiForWeatherCode(1213), weather.HourlyForecast[1].Emoji)
+	assert.Len(t, weather.DailyForecast, 2)
+	assert.Equal(t, -3.1, weather.DailyForecast[0].MintempC)
+	assert.Equal(t, getEmojiForWeatherCode(1003), weather.DailyForecast[0].Emoji)
+	assert.Equal(t, 1.2, weather.DailyForecast[1].TotalprecipMm)
+	assert.Equal(t, "metno", weather.Source.Provider)
+
+	// The caching headers are stored with the entry
+	entry, found := cache.Get(cacheKey("metno", config.Location))
+	assert.True(t, found)
+	assert.Equal(t, lastModified, entry.LastModified)
+	assert.False(t, entry.Expires.IsZero())
+
+	// Served from the cache until the entry expires
+	_, err = provider.GetWeather(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// Once expired, the forecast is revalidated with If-Modified-Since
+	entry.Expires = time.Now().Add(-time.Minute)
+	cache.Entries[cacheKey("metno", config.Location)] = *entry
+	revalidated, err := provider.GetWeather(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, -2.5, revalidated.Current.TempC)
+	entry, _ = cache.Get(cacheKey("metno", config.Location))
+	assert.True(t, entry.Expires.After(time.Now()))
+}
+
+func TestMetnoProvider_GetWeather_RequestError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	originalURL := metnoURL
+	metnoURL = server.URL
+	defer func() { metnoURL = originalURL }()
+
+	// The cause of a failed request is kept
+	_, err := (&metnoProvider{cache: newMemoryCache()}).GetWeather(&Config{Location: "59.9139,10.7522", NoCache: true})
+	var urlErr *url.Error
+	assert.ErrorAs(t, err, &urlErr)
+	assert.ErrorContains(t, err, "failed to make HTTP request: ")
+}
+
+func TestMetnoResponse_ToWeather_TimeZone(t *testing.T) {
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local, _ = time.LoadLocation("Asia/Tokyo")
+
+	var response MetnoResponse
+	err := json.Unmarshal([]byte(`{"properties": {"timeseries": [
+		{"time": "2025-01-12T14:00:00Z", "data": {"instant": {"details": {"air_temperature": 1.0}}}},
+		{"time": "2025-01-12T16:00:00Z", "data": {"instant": {"details": {"air_temperature": 2.0}}}}
+	]}}`), &response)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// Without a time zone for the location, the days are split in the machine's, as they are rendered
+	weather := response.toWeather(WeatherLocation{Lat: 35.68, Lon: 139.69})
+	if assert.Len(t, weather.DailyForecast, 2) {
+		assert.Equal(t, "2025-01-12", weather.DailyForecast[0].Date)
+		assert.Equal(t, "2025-01-13", weather.DailyForecast[1].Date)
+	}
+
+	// The location's time zone is used when it is known
+	weather = response.toWeather(WeatherLocation{TzID: "UTC"})
+	assert.Len(t, weather.DailyForecast, 1)
+}
+
+func TestGetEmojiForMetnoSymbol(t *testing.T) {
+	assert.Equal(t, getEmojiForWeatherCode(1000), getEmojiForMetnoSymbol("clearsky_day"))
+	assert.Equal(t, getEmojiForWeatherCode(1195), getEmojiForMetnoSymbol("heavyrain"))
+	assert.Equal(t, "❓", getEmojiForMetnoSymbol("unknown"))
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// conditionTextToCode maps keywords of textual weather conditions to the weatherapi.com
+// condition code sharing the same icon. The entries are checked in order, so the more
+// specific phrases come first.
+var conditionTextToCode = []struct {
+	Keyword string
+	Code    int
+}{
+	{"thunder", 1087},
+	{"blizzard", 1117},
+	{"blowing snow", 1114},
+	{"freezing rain", 1201},
+	{"freezing drizzle", 1168},
+	{"sleet", 1069},
+	{"ice pellets", 1237},
+	{"heavy snow", 1225},
+	{"snow showers", 1255},
+	{"snow", 1219},
+	{"heavy rain", 1195},
+	{"showers", 1243},
+	{"drizzle", 1153},
+	{"rain", 1189},
+	{"freezing fog", 1147},
+	{"fog", 1135},
+	{"haze", 1030},
+	{"smoke", 1030},
+	{"mist", 1030},
+	{"overcast", 1009},
+	{"mostly cloudy", 1006},
+	{"partly cloudy", 1003},
+	{"partly sunny", 1003},
+	{"mostly sunny", 1000},
+	{"mostly clear", 1000},
+	{"cloudy", 1006},
+	{"sunny", 1000},
+	{"clear", 1000},
+	{"fair", 1000},
+}
+
+// getEmojiForConditionText returns the emoji for a textual weather condition (e.g. "Chance Light Rain").
+func getEmojiForConditionText(text string) string {
+	text = strings.ToLower(text)
+	for _, entry := range conditionTextToCode {
+		if strings.Contains(text, entry.Keyword) {
+			return getEmojiForWeatherCode(entry.Code)
+		}
+	}
+	return "❓" // Default emoji for unknown conditions
+}
+
+// nwsPointsURL is the base URL for the National Weather Service points endpoint.
+var nwsPointsURL = "https://api.weather.gov/points"
+
+// NWSPointResponse represents the structure of the NWS /points response.
+type NWSPointResponse struct {
+	Properties struct {
+		GridID           string `json:"gridId"`
+		GridX            int    `json:"gridX"`
+		GridY            int    `json:"gridY"`
+		ForecastHourly   string `json:"forecastHourly"`
+		TimeZone         string `json:"timeZone"`
+		RelativeLocation struct {
+			Properties struct {
+				City  string `json:"city"`
+				State string `json:"state"`
+			} `json:"properties"`
+		} `json:"relativeLocation"`
+	} `json:"properties"`
+}
+
+// NWSForecastResponse represents the structure of the NWS gridpoint hourly forecast response (units=si).
+type NWSForecastResponse struct {
+	Properties struct {
+		Updated time.Time   `json:"updated"`
+		Periods []NWSPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+// NWSPeriod represents a single period of the NWS forecast.
+type NWSPeriod struct {
+	StartTime                  time.Time `json:"startTime"`
+	IsDaytime                  bool      `json:"isDaytime"`
+	Temperature                float64   `json:"temperature"`
+	ProbabilityOfPrecipitation NWSValue  `json:"probabilityOfPrecipitation"`
+	RelativeHumidity           NWSValue  `json:"relativeHumidity"`
+	WindSpeed                  string    `json:"windSpeed"`
+	WindDirection              string    `json:"windDirection"`
+	ShortForecast              string    `json:"shortForecast"`
+}
+
+// NWSValue represents a quantitative value of the NWS API.
+type NWSValue struct {
+	UnitCode string  `json:"unitCode"`
+	Value    float64 `json:"value"`
+}
+
+// nwsPoint is the permanently cached resolution of a location to an NWS gridpoint.
+type nwsPoint struct {
+	Location       WeatherLocation `json:"location"`
+	ForecastHourly string          `json:"forecastHourly"`
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "nws",
+		Description: "US National Weather Service API (keyless, US only)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &nwsProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly},
+	})
+}
+
+// nwsProvider is the implementation of WeatherProvider that uses the US National Weather Service API.
+type nwsProvider struct {
+	cache *Cache
+}
+
+// GetWeather fetches the hourly forecast from api.weather.gov for the location in the config.
+// The location is resolved to a forecast gridpoint once and that resolution is cached permanently.
+func (p *nwsProvider) GetWeather(c *Config) (*Weather, error) {
+	key := cacheKey("nws", c.Location)
+
+	// Check cache first
+	if !c.NoCache {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
+			return entry.Weather, nil
+		}
+	}
+
+	point, err := p.resolvePoint(c)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newIdentifiedRequest(c, point.ForecastHourly+"?units=si")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/geo+json")
+
+	var forecast NWSForecastResponse
+	if err := doJSONRequest(req, &forecast); err != nil {
+		return nil, err
+	}
+
+	weather := forecast.toWeather(point.Location)
+
+	// Save to cache
+	if err := p.cache.Set(key, weather); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *nwsProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// resolvePoint resolves the configured location to an NWS gridpoint through the /points endpoint.
+func (p *nwsProvider) resolvePoint(c *Config) (*nwsPoint, error) {
+	key := cacheKey("nws-point", c.Location)
+
+	point := &nwsPoint{}
+	if p.cache.GetPermanent(key, point) {
+		return point, nil
+	}
+
+	location, err := resolveLocation(c.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newIdentifiedRequest(c, fmt.Sprintf("%s/%s,%s", nwsPointsURL,
+		strconv.FormatFloat(location.Lat, 'f', 4, 64), strconv.FormatFloat(location.Lon, 'f', 4, 64)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/geo+json")
+
+	var response NWSPointResponse
+	if err := doJSONRequest(req, &response); err != nil {
+		return nil, fmt.Errorf("failed to resolve NWS gridpoint (only US locations are supported): %w", err)
+	}
+
+	point.Location = location
+	point.Location.TzID = response.Properties.TimeZone
+	if _, _, ok := parseCoordinates(c.Location); ok {
+		point.Location.Name = response.Properties.RelativeLocation.Properties.City
+		point.Location.Region = response.Properties.RelativeLocation.Properties.State
+		point.Location.Country = "United States"
+	}
+	point.ForecastHourly = response.Properties.ForecastHourly
+
+	if err := p.cache.SetPermanent(key, point); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+	return point, nil
+}
+
+// toWeather maps the NWSForecastResponse to the provider-neutral Weather struct.
+// The NWS hourly forecast has no separate current conditions, so the first period is used.
+func (r *NWSForecastResponse) toWeather(location WeatherLocation) *Weather {
+	weather := &Weather{
+		Location: location,
+		Source: WeatherSource{
+			Provider:    "nws",
+			Attribution: "Data from the National Weather Service (NOAA)",
+			URL:         "https://www.weather.gov/",
+		},
+	}
+
+	for i, period := range r.Properties.Periods {
+		windKph := parseNWSWindSpeed(period.WindSpeed)
+		if i == 0 {
+			weather.Location.LocaltimeEpoch = period.StartTime.Unix()
+			weather.Current = WeatherCurrent{
+				Location:         location.Name,
+				Country:          location.Country,
+				LastUpdatedEpoch: r.Properties.Updated.Unix(),
+				Emoji:            getEmojiForConditionText(period.ShortForecast),
+				Condition:        period.ShortForecast,
+				IsDay:            period.IsDaytime,
+				TempC:            period.Temperature,
+				FeelslikeC:       period.Temperature,
+				Humidity:         int(period.RelativeHumidity.Value),
+				WindKph:          windKph,
+				WindDir:          period.WindDirection,
+			}
+		}
+
+		weather.HourlyForecast = append(weather.HourlyForecast, HourlyForecast{
+			TimeEpoch:    period.StartTime.Unix(),
+			Emoji:        getEmojiForConditionText(period.ShortForecast),
+			Condition:    period.ShortForecast,
+			IsDay:        period.IsDaytime,
+			TempC:        period.Temperature,
+			FeelslikeC:   period.Temperature,
+			Humidity:     int(period.RelativeHumidity.Value),
+			WindKph:      windKph,
+			WindDir:      period.WindDirection,
+			ChanceOfRain: int(period.ProbabilityOfPrecipitation.Value),
+		})
+	}
+
+	return weather
+}
+
+// parseNWSWindSpeed parses an NWS wind speed such as "15 km/h" or "10 to 20 km/h",
+// returning the highest value.
+func parseNWSWindSpeed(speed string) float64 {
+	var result float64
+	for _, field := range strings.Fields(speed) {
+		if value, err := strconv.ParseFloat(field, 64); err == nil {
+			result = max(result, value)
+		}
+	}
+	return result
+}
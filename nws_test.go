@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNWSProvider_GetWeather(t *testing.T) {
+	pointRequests := 0
+
+	// Create a mock HTTP server for the points and the hourly forecast endpoint
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			t.Errorf("Expected a User-Agent header")
+		}
+		switch r.URL.Path {
+		case "/points/39.0473,-95.6752":
+			pointRequests++
+			w.Write([]byte(`{"properties": {"gridId": "TOP", "gridX": 31, "gridY": 80,
+				"forecastHourly": "` + server.URL + `/gridpoints/TOP/31,80/forecast/hourly",
+				"timeZone": "America/Chicago",
+				"relativeLocation": {"properties": {"city": "Topeka", "state": "KS"}}}}`))
+		case "/gridpoints/TOP/31,80/forecast/hourly":
+			if r.URL.Query().Get("units") != "si" {
+				t.Errorf("Expected query parameter 'units' to be 'si', got: %s", r.URL.Query().Get("units"))
+			}
+			w.Write([]byte(`{"properties": {"updated": "2025-01-12T18:00:00Z", "periods": [
+				{"startTime": "2025-01-12T13:00:00-06:00", "isDaytime": true, "temperature": 3,
+					"probabilityOfPrecipitation": {"value": 20}, "relativeHumidity": {"value": 70},
+					"windSpeed": "15 km/h", "windDirection": "NW", "shortForecast": "Mostly Cloudy"},
+				{"startTime": "2025-01-12T14:00:00-06:00", "isDaytime": true, "temperature": 2,
+					"probabilityOfPrecipitation": {"value": 60}, "relativeHumidity": {"value": 85},
+					"windSpeed": "10 to 20 km/h", "windDirection": "N", "shortForecast": "Chance Light Snow"}
+			]}}`))
+		default:
+			t.Errorf("Unexpected request to: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	originalURL := nwsPointsURL
+	nwsPointsURL = server.URL + "/points"
+	defer func() { nwsPointsURL = originalURL }()
+
+	tempDir, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "cache.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider := &nwsProvider{cache: cache}
+	config := &Config{Location: "39.0473,-95.6752", NoCache: true}
+	weather, err := provider.GetWeather(config)
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	// Assertions
+	assert.Equal(t, "Topeka", weather.Current.Location)
+	assert.Equal(t, "America/Chicago", weather.Location.TzID)
+	assert.Equal(t, 3.0, weather.Current.TempC)
+	assert.Equal(t, "Mostly Cloudy", weather.Current.Condition)
+	assert.Equal(t, getEmojiForWeatherCode(1006), weather.Current.Emoji)
+	assert.Len(t, weather.HourlyForecast, 2)
+	assert.Equal(t, 20.0, weather.HourlyForecast[1].WindKph)
+	assert.Equal(t, 60, weather.HourlyForecast[1].ChanceOfRain)
+	assert.Equal(t, getEmojiForWeatherCode(1219), weather.HourlyForecast[1].Emoji)
+	assert.Equal(t, "nws", weather.Source.Provider)
+
+	// The gridpoint resolution is cached permanently and survives cleaning
+	cache.Clean(0)
+	_, err = provider.GetWeather(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pointRequests)
+}
+
+func TestGetEmojiForConditionText(t *testing.T) {
+	assert.Equal(t, getEmojiForWeatherCode(1000), getEmojiForConditionText("Sunny"))
+	assert.Equal(t, getEmojiForWeatherCode(1003), getEmojiForConditionText("Partly Cloudy"))
+	assert.Equal(t, getEmojiForWeatherCode(1087), getEmojiForConditionText("Slight Chance Rain Showers And Thunderstorms"))
+	assert.Equal(t, "❓", getEmojiForConditionText("Volcanic Ash"))
+}
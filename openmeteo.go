@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wmoCodeToConditionMap maps the WMO weather codes used by Open-Meteo to a description
+// and to the weatherapi.com condition code sharing the same icon.
+var wmoCodeToConditionMap = map[int]conditionMapping{
+	0:  {"Clear sky", 1000},
+	1:  {"Mainly clear", 1003},
+	2:  {"Partly cloudy", 1003},
+	3:  {"Overcast", 1009},
+	45: {"Fog", 1135},
+	48: {"Depositing rime fog", 1147},
+	51: {"Light drizzle", 1150},
+	53: {"Moderate drizzle", 1153},
+	55: {"Dense drizzle", 1153},
+	56: {"Light freezing drizzle", 1168},
+	57: {"Dense freezing drizzle", 1171},
+	61: {"Slight rain", 1183},
+	63: {"Moderate rain", 1189},
+	65: {"Heavy rain", 1195},
+	66: {"Light freezing rain", 1198},
+	67: {"Heavy freezing rain", 1201},
+	71: {"Slight snow fall", 1213},
+	73: {"Moderate snow fall", 1219},
+	75: {"Heavy snow fall", 1225},
+	77: {"Snow grains", 1237},
+	80: {"Slight rain showers", 1240},
+	81: {"Moderate rain showers", 1243},
+	82: {"Violent rain showers", 1246},
+	85: {"Slight snow showers", 1255},
+	86: {"Heavy snow showers", 1258},
+	95: {"Thunderstorm", 1273},
+	96: {"Thunderstorm with slight hail", 1276},
+	99: {"Thunderstorm with heavy hail", 1276},
+}
+
+// getEmojiForWMOCode returns the emoji for a given WMO weather code.
+func getEmojiForWMOCode(code int) string {
+	if condition, ok := wmoCodeToConditionMap[code]; ok {
+		return getEmojiForWeatherCode(condition.Code)
+	}
+	return "❓" // Default emoji for unknown codes
+}
+
+// getTextForWMOCode returns the description of a given WMO weather code.
+func getTextForWMOCode(code int) string {
+	return wmoCodeToConditionMap[code].Text
+}
+
+// openMeteoMaxDays is the maximum number of forecast days of the Open-Meteo forecast endpoint.
+const openMeteoMaxDays = 16
+
+// openMeteoURL is the base URL for the Open-Meteo forecast endpoint.
+var openMeteoURL = "https://api.open-meteo.com/v1/forecast"
+
+// openMeteoGeocodingURL is the base URL for the Open-Meteo geocoding endpoint.
+var openMeteoGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+
+// OpenMeteoResponse represents the structure of the Open-Meteo forecast response
+// when requested with timeformat=unixtime.
+type OpenMeteoResponse struct {
+	Latitude         float64          `json:"latitude"`
+	Longitude        float64          `json:"longitude"`
+	Timezone         string           `json:"timezone"`
+	UtcOffsetSeconds int              `json:"utc_offset_seconds"`
+	Current          OpenMeteoCurrent `json:"current"`
+	Hourly           OpenMeteoHourly  `json:"hourly"`
+	Daily            OpenMeteoDaily   `json:"daily"`
+}
+
+// OpenMeteoCurrent represents the current weather conditions.
+type OpenMeteoCurrent struct {
+	Time                int64   `json:"time"`
+	Temperature2m       float64 `json:"temperature_2m"`
+	RelativeHumidity2m  int     `json:"relative_humidity_2m"`
+	ApparentTemperature float64 `json:"apparent_temperature"`
+	IsDay               int     `json:"is_day"`
+	Precipitation       float64 `json:"precipitation"`
+	WeatherCode         int     `json:"weather_code"`
+	CloudCover          int     `json:"cloud_cover"`
+	PressureMsl         float64 `json:"pressure_msl"`
+	WindSpeed10m        float64 `json:"wind_speed_10m"`
+	WindDirection10m    int     `json:"wind_direction_10m"`
+	UvIndex             float64 `json:"uv_index"`
+}
+
+// OpenMeteoHourly represents the hourly forecast, one slice element per hour.
+type OpenMeteoHourly struct {
+	Time                     []int64   `json:"time"`
+	Temperature2m            []float64 `json:"temperature_2m"`
+	RelativeHumidity2m       []int     `json:"relative_humidity_2m"`
+	ApparentTemperature      []float64 `json:"apparent_temperature"`
+	PrecipitationProbability []int     `json:"precipitation_probability"`
+	Precipitation            []float64 `json:"precipitation"`
+	WeatherCode              []int     `json:"weather_code"`
+	WindSpeed10m             []float64 `json:"wind_speed_10m"`
+	WindDirection10m         []int     `json:"wind_direction_10m"`
+	IsDay                    []int     `json:"is_day"`
+}
+
+// OpenMeteoDaily represents the daily forecast, one slice element per day.
+type OpenMeteoDaily struct {
+	Time                        []int64   `json:"time"`
+	WeatherCode                 []int     `json:"weather_code"`
+	Temperature2mMax            []float64 `json:"temperature_2m_max"`
+	Temperature2mMin            []float64 `json:"temperature_2m_min"`
+	Sunrise                     []int64   `json:"sunrise"`
+	Sunset                      []int64   `json:"sunset"`
+	UvIndexMax                  []float64 `json:"uv_index_max"`
+	PrecipitationSum            []float64 `json:"precipitation_sum"`
+	SnowfallSum                 []float64 `json:"snowfall_sum"`
+	PrecipitationProbabilityMax []int     `json:"precipitation_probability_max"`
+	WindSpeed10mMax             []float64 `json:"wind_speed_10m_max"`
+}
+
+// OpenMeteoGeocodingResponse represents the Open-Meteo geocoding search response.
+type OpenMeteoGeocodingResponse struct {
+	Results []struct {
+		Name      string  `json:"name"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Country   string  `json:"country"`
+		Admin1    string  `json:"admin1"`
+		Timezone  string  `json:"timezone"`
+	} `json:"results"`
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "openmeteo",
+		Description: "Open-Meteo forecast API (keyless)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &openMeteoProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily},
+	})
+}
+
+// openMeteoProvider is the implementation of WeatherProvider that uses the keyless Open-Meteo API.
+type openMeteoProvider struct {
+	cache *Cache
+}
+
+// GetWeather fetches weather forecast data from Open-Meteo for the location in the config.
+// The location can be given as "lat,lon" or as a place name, which is resolved through
+// the Open-Meteo geocoding API.
+func (p *openMeteoProvider) GetWeather(c *Config) (*Weather, error) {
+	days := c.ForecastDaysToFetch(openMeteoMaxDays)
+	key := cacheKey("openmeteo", c.Location) + "|" + strconv.Itoa(days)
+
+	// Check cache first
+	if !c.NoCache {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
+			return entry.Weather, nil
+		}
+	}
+
+	location, err := resolveLocation(c.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(location.Lat, 'f', -1, 64))
+	query.Set("longitude", strconv.FormatFloat(location.Lon, 'f', -1, 64))
+	query.Set("current", "temperature_2m,relative_humidity_2m,apparent_temperature,is_day,precipitation,weather_code,cloud_cover,pressure_msl,wind_speed_10m,wind_direction_10m,uv_index")
+	query.Set("hourly", "temperature_2m,relative_humidity_2m,apparent_temperature,precipitation_probability,precipitation,weather_code,wind_speed_10m,wind_direction_10m,is_day")
+	query.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,sunrise,sunset,uv_index_max,precipitation_sum,snowfall_sum,precipitation_probability_max,wind_speed_10m_max")
+	query.Set("timezone", "auto")
+	query.Set("timeformat", "unixtime")
+	query.Set("forecast_days", strconv.Itoa(days))
+
+	var forecast OpenMeteoResponse
+	if err := fetchJSON(openMeteoURL+"?"+query.Encode(), &forecast); err != nil {
+		return nil, err
+	}
+
+	weather := forecast.toWeather(location)
+
+	// Save to cache
+	if err := p.cache.Set(key, weather); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *openMeteoProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// toWeather maps the OpenMeteoResponse to the provider-neutral Weather struct.
+func (r *OpenMeteoResponse) toWeather(location WeatherLocation) *Weather {
+	zone := time.FixedZone(r.Timezone, r.UtcOffsetSeconds)
+	location.TzID = r.Timezone
+	location.LocaltimeEpoch = r.Current.Time
+
+	var hourlyForecasts []HourlyForecast
+	for i, timeEpoch := range r.Hourly.Time {
+		hourlyForecasts = append(hourlyForecasts, HourlyForecast{
+			TimeEpoch:    timeEpoch,
+			Emoji:        getEmojiForWMOCode(valueAt(r.Hourly.WeatherCode, i)),
+			Condition:    getTextForWMOCode(valueAt(r.Hourly.WeatherCode, i)),
+			IsDay:        valueAt(r.Hourly.IsDay, i) == 1,
+			TempC:        valueAt(r.Hourly.Temperature2m, i),
+			FeelslikeC:   valueAt(r.Hourly.ApparentTemperature, i),
+			Humidity:     valueAt(r.Hourly.RelativeHumidity2m, i),
+			WindKph:      valueAt(r.Hourly.WindSpeed10m, i),
+			WindDir:      windDirection(valueAt(r.Hourly.WindDirection10m, i)),
+			PrecipMm:     valueAt(r.Hourly.Precipitation, i),
+			ChanceOfRain: valueAt(r.Hourly.PrecipitationProbability, i),
+		})
+	}
+
+	var dailyForecasts []DailyForecast
+	for i, dateEpoch := range r.Daily.Time {
+		dailyForecasts = append(dailyForecasts, DailyForecast{
+			DateEpoch:     dateEpoch,
+			Date:          time.Unix(dateEpoch, 0).In(zone).Format("2006-01-02"),
+			Emoji:         getEmojiForWMOCode(valueAt(r.Daily.WeatherCode, i)),
+			Condition:     getTextForWMOCode(valueAt(r.Daily.WeatherCode, i)),
+			MaxtempC:      valueAt(r.Daily.Temperature2mMax, i),
+			MintempC:      valueAt(r.Daily.Temperature2mMin, i),
+			AvgtempC:      (valueAt(r.Daily.Temperature2mMax, i) + valueAt(r.Daily.Temperature2mMin, i)) / 2,
+			MaxwindKph:    valueAt(r.Daily.WindSpeed10mMax, i),
+			TotalprecipMm: valueAt(r.Daily.PrecipitationSum, i),
+			TotalsnowCm:   valueAt(r.Daily.SnowfallSum, i),
+			ChanceOfRain:  valueAt(r.Daily.PrecipitationProbabilityMax, i),
+			Uv:            valueAt(r.Daily.UvIndexMax, i),
+			Astro: WeatherAstro{
+				SunriseEpoch: valueAt(r.Daily.Sunrise, i),
+				SunsetEpoch:  valueAt(r.Daily.Sunset, i),
+			},
+		})
+	}
+
+	return &Weather{
+		Location: location,
+		Current: WeatherCurrent{
+			Location:         location.Name,
+			Country:          location.Country,
+			LastUpdatedEpoch: r.Current.Time,
+			Emoji:            getEmojiForWMOCode(r.Current.WeatherCode),
+			Condition:        getTextForWMOCode(r.Current.WeatherCode),
+			IsDay:            r.Current.IsDay == 1,
+			TempC:            r.Current.Temperature2m,
+			FeelslikeC:       r.Current.ApparentTemperature,
+			Humidity:         r.Current.RelativeHumidity2m,
+			WindKph:          r.Current.WindSpeed10m,
+			WindDegree:       r.Current.WindDirection10m,
+			WindDir:          windDirection(r.Current.WindDirection10m),
+			PressureMb:       r.Current.PressureMsl,
+			PrecipMm:         r.Current.Precipitation,
+			Cloud:            r.Current.CloudCover,
+			Uv:               r.Current.UvIndex,
+		},
+		HourlyForecast: hourlyForecasts,
+		DailyForecast:  dailyForecasts,
+		Source: WeatherSource{
+			Provider:    "openmeteo",
+			Attribution: "Weather data by Open-Meteo.com",
+			URL:         "https://open-meteo.com/",
+		},
+	}
+}
+
+// resolveLocation turns a configured location into coordinates.
+// Locations given as "lat,lon" are used as-is, anything else is looked up
+// through the Open-Meteo geocoding API.
+func resolveLocation(location string) (WeatherLocation, error) {
+	if lat, lon, ok := parseCoordinates(location); ok {
+		return WeatherLocation{Name: location, Lat: lat, Lon: lon}, nil
+	}
+	if location == "" || strings.HasPrefix(location, "auto:") {
+		return WeatherLocation{}, fmt.Errorf("location %q must be a place name or \"lat,lon\" coordinates", location)
+	}
+
+	query := url.Values{}
+	query.Set("name", location)
+	query.Set("count", "1")
+	query.Set("format", "json")
+
+	var geocoding OpenMeteoGeocodingResponse
+	if err := fetchJSON(openMeteoGeocodingURL+"?"+query.Encode(), &geocoding); err != nil {
+		return WeatherLocation{}, err
+	}
+	if len(geocoding.Results) == 0 {
+		return WeatherLocation{}, fmt.Errorf("location %q not found", location)
+	}
+
+	result := geocoding.Results[0]
+	return WeatherLocation{
+		Name:    result.Name,
+		Region:  result.Admin1,
+		Country: result.Country,
+		Lat:     result.Latitude,
+		Lon:     result.Longitude,
+		TzID:    result.Timezone,
+	}, nil
+}
+
+// parseCoordinates parses a "lat,lon" string.
+func parseCoordinates(location string) (float64, float64, bool) {
+	parts := strings.Split(location, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// valueAt returns the element at index i, or the zero value if the slice is too short.
+// Open-Meteo omits or shortens arrays for variables that are unavailable for a location.
+func valueAt[T any](values []T, i int) T {
+	var zero T
+	if i < len(values) {
+		return values[i]
+	}
+	return zero
+}
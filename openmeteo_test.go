@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMeteoProvider_GetWeather(t *testing.T) {
+
+	// Create a mock HTTP server for both the geocoding and the forecast endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/search":
+			if r.URL.Query().Get("name") != "Berlin" {
+				t.Errorf("Expected query parameter 'name' to be 'Berlin', got: %s", r.URL.Query().Get("name"))
+			}
+			w.Write([]byte(`{"results":[{"name":"Berlin","latitude":52.52,"longitude":13.41,"country":"Germany","admin1":"Land Berlin","timezone":"Europe/Berlin"}]}`))
+		case "/v1/forecast":
+			if r.URL.Query().Get("latitude") != "52.52" || r.URL.Query().Get("longitude") != "13.41" {
+				t.Errorf("Expected coordinates 52.52,13.41, got: %s,%s", r.URL.Query().Get("latitude"), r.URL.Query().Get("longitude"))
+			}
+			if r.URL.Query().Get("timeformat") != "unixtime" {
+				t.Errorf("Expected query parameter 'timeformat' to be 'unixtime', got: %s", r.URL.Query().Get("timeformat"))
+			}
+
+			// Provide a sample JSON response
+			sampleResponse := OpenMeteoResponse{
+				Timezone:         "Europe/Berlin",
+				UtcOffsetSeconds: 3600,
+				Current: OpenMeteoCurrent{
+					Time:                1736705400,
+					Temperature2m:       3.5,
+					RelativeHumidity2m:  81,
+					ApparentTemperature: 0.4,
+					WeatherCode:         3,
+					WindDirection10m:    225,
+				},
+				Hourly: OpenMeteoHourly{
+					Time:          []int64{1736704800, 1736708400},
+					Temperature2m: []float64{3.4, 2.9},
+					WeatherCode:   []int{3, 61},
+				},
+				Daily: OpenMeteoDaily{
+					Time:             []int64{1736636400},
+					WeatherCode:      []int{61},
+					Temperature2mMax: []float64{4.0},
+					Temperature2mMin: []float64{-1.0},
+					Sunrise:          []int64{1736667120},
+					Sunset:           []int64{1736696040},
+				},
+			}
+			json.NewEncoder(w).Encode(sampleResponse)
+		default:
+			t.Errorf("Unexpected request to: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	originalURL, originalGeocodingURL := openMeteoURL, openMeteoGeocodingURL
+	openMeteoURL = server.URL + "/v1/forecast"
+	openMeteoGeocodingURL = server.URL + "/v1/search"
+	defer func() { openMeteoURL, openMeteoGeocodingURL = originalURL, originalGeocodingURL }()
+
+	tempDir, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "cache.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider := &openMeteoProvider{cache: cache}
+	weather, err := provider.GetWeather(&Config{Location: "Berlin"})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	// Assertions
+	assert.Equal(t, "Berlin", weather.Current.Location)
+	assert.Equal(t, "Germany", weather.Current.Country)
+	assert.Equal(t, 3.5, weather.Current.TempC)
+	assert.Equal(t, 81, weather.Current.Humidity)
+	assert.Equal(t, "SW", weather.Current.WindDir)
+	assert.Equal(t, "Overcast", weather.Current.Condition)
+	assert.Equal(t, getEmojiForWeatherCode(1009), weather.Current.Emoji)
+	assert.Len(t, weather.HourlyForecast, 2)
+	assert.Equal(t, getEmojiForWeatherCode(1183), weather.HourlyForecast[1].Emoji)
+	assert.Len(t, weather.DailyForecast, 1)
+	assert.Equal(t, "2025-01-12", weather.DailyForecast[0].Date)
+	assert.Equal(t, "08:32", weather.DailyForecast[0].Astro.inLocale(LocaleFormat{clock: "15:04", zone: weather.TimeZone()}).Sunrise())
+	assert.Equal(t, "openmeteo", weather.Source.Provider)
+
+	// A second call is served from the cache
+	server.Close()
+	cached, err := provider.GetWeather(&Config{Location: "Berlin"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, cached.Current.TempC)
+}
+
+func TestGetEmojiForWMOCode(t *testing.T) {
+	assert.Equal(t, getEmojiForWeatherCode(1000), getEmojiForWMOCode(0))
+	assert.Equal(t, getEmojiForWeatherCode(1276), getEmojiForWMOCode(99))
+	assert.Equal(t, "❓", getEmojiForWMOCode(42))
+}
+
+func TestParseCoordinates(t *testing.T) {
+	lat, lon, ok := parseCoordinates("48.8567, 2.3508")
+	assert.True(t, ok)
+	assert.Equal(t, 48.8567, lat)
+	assert.Equal(t, 2.3508, lon)
+
+	_, _, ok = parseCoordinates("Paris")
+	assert.False(t, ok)
+}
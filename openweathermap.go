@@ -0,0 +1,406 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// owmCodeToEmojiMap stores the mapping from OpenWeatherMap condition codes to emojis (see samples/emojis.json).
+var owmCodeToEmojiMap = map[int]string{
+	800: "☀️",
+	801: "🌤️",
+	802: "☁️",
+	803: "☁️",
+	804: "☁️",
+	500: "🌦️",
+	501: "🌧️",
+	502: "🌧️",
+	503: "🌧️",
+	504: "🌧️",
+	511: "🌨️",
+	520: "🌧️",
+	521: "🌧️",
+	522: "🌧️",
+	531: "🌧️",
+	200: "⛈️",
+	201: "⛈️",
+	202: "⛈️",
+	210: "🌩️",
+	211: "🌩️",
+	212: "🌩️",
+	221: "🌩️",
+	230: "⛈️",
+	231: "⛈️",
+	232: "⛈️",
+	300: "🌦️",
+	600: "❄️",
+	601: "❄️",
+	602: "❄️",
+	700: "🌫️",
+	701: "🌫️",
+	711: "🌫️",
+	721: "🌫️",
+	731: "🌫️",
+	741: "🌫️",
+	751: "🌫️",
+	761: "🌫️",
+	762: "🌋",
+	771: "🌬️",
+	781: "🌪️",
+}
+
+// getEmojiForOWMCode returns the emoji for a given OpenWeatherMap condition code.
+// Codes missing from the table fall back to the first code of their group (e.g. 615 -> 600).
+func getEmojiForOWMCode(code int) string {
+	if emoji, ok := owmCodeToEmojiMap[code]; ok {
+		return emoji
+	}
+	if emoji, ok := owmCodeToEmojiMap[code/100*100]; ok {
+		return emoji
+	}
+	return "❓" // Default emoji for unknown codes
+}
+
+// owmOneCallURL is the base URL for the OpenWeatherMap One Call endpoint.
+var owmOneCallURL = "https://api.openweathermap.org/data/3.0/onecall"
+
+// owmGeocodingURL is the base URL for the OpenWeatherMap direct geocoding endpoint.
+var owmGeocodingURL = "https://api.openweathermap.org/geo/1.0/direct"
+
+// OWMResponse represents the structure of the OpenWeatherMap One Call response (units=metric).
+type OWMResponse struct {
+	Lat            float64    `json:"lat"`
+	Lon            float64    `json:"lon"`
+	Timezone       string     `json:"timezone"`
+	TimezoneOffset int        `json:"timezone_offset"`
+	Current        OWMCurrent `json:"current"`
+	Hourly         []OWMHour  `json:"hourly"`
+	Daily          []OWMDay   `json:"daily"`
+}
+
+// OWMCondition represents an OpenWeatherMap weather condition.
+type OWMCondition struct {
+	ID          int    `json:"id"`
+	Main        string `json:"main"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+}
+
+// OWMPrecipitation represents the precipitation volume of the last hour.
+type OWMPrecipitation struct {
+	OneHour float64 `json:"1h"`
+}
+
+// OWMCurrent represents the current weather conditions.
+type OWMCurrent struct {
+	Dt         int64            `json:"dt"`
+	Sunrise    int64            `json:"sunrise"`
+	Sunset     int64            `json:"sunset"`
+	Temp       float64          `json:"temp"`
+	FeelsLike  float64          `json:"feels_like"`
+	Pressure   float64          `json:"pressure"`
+	Humidity   int              `json:"humidity"`
+	DewPoint   float64          `json:"dew_point"`
+	Uvi        float64          `json:"uvi"`
+	Clouds     int              `json:"clouds"`
+	Visibility float64          `json:"visibility"`
+	WindSpeed  float64          `json:"wind_speed"`
+	WindDeg    int              `json:"wind_deg"`
+	WindGust   float64          `json:"wind_gust"`
+	Weather    []OWMCondition   `json:"weather"`
+	Rain       OWMPrecipitation `json:"rain"`
+	Snow       OWMPrecipitation `json:"snow"`
+}
+
+// OWMHour represents hourly forecast data.
+type OWMHour struct {
+	Dt         int64            `json:"dt"`
+	Temp       float64          `json:"temp"`
+	FeelsLike  float64          `json:"feels_like"`
+	Pressure   float64          `json:"pressure"`
+	Humidity   int              `json:"humidity"`
+	DewPoint   float64          `json:"dew_point"`
+	Uvi        float64          `json:"uvi"`
+	Clouds     int              `json:"clouds"`
+	Visibility float64          `json:"visibility"`
+	WindSpeed  float64          `json:"wind_speed"`
+	WindDeg    int              `json:"wind_deg"`
+	WindGust   float64          `json:"wind_gust"`
+	Weather    []OWMCondition   `json:"weather"`
+	Pop        float64          `json:"pop"`
+	Rain       OWMPrecipitation `json:"rain"`
+	Snow       OWMPrecipitation `json:"snow"`
+}
+
+// OWMDay represents the daily summary.
+type OWMDay struct {
+	Dt        int64   `json:"dt"`
+	Sunrise   int64   `json:"sunrise"`
+	Sunset    int64   `json:"sunset"`
+	Moonrise  int64   `json:"moonrise"`
+	Moonset   int64   `json:"moonset"`
+	MoonPhase float64 `json:"moon_phase"`
+	Summary   string  `json:"summary"`
+	Temp      struct {
+		Day   float64 `json:"day"`
+		Min   float64 `json:"min"`
+		Max   float64 `json:"max"`
+		Night float64 `json:"night"`
+		Eve   float64 `json:"eve"`
+		Morn  float64 `json:"morn"`
+	} `json:"temp"`
+	Pressure  float64        `json:"pressure"`
+	Humidity  int            `json:"humidity"`
+	DewPoint  float64        `json:"dew_point"`
+	WindSpeed float64        `json:"wind_speed"`
+	WindDeg   int            `json:"wind_deg"`
+	WindGust  float64        `json:"wind_gust"`
+	Weather   []OWMCondition `json:"weather"`
+	Clouds    int            `json:"clouds"`
+	Pop       float64        `json:"pop"`
+	Rain      float64        `json:"rain"`
+	Snow      float64        `json:"snow"`
+	Uvi       float64        `json:"uvi"`
+}
+
+// OWMGeocodingResult represents a single entry of the OpenWeatherMap geocoding response.
+type OWMGeocodingResult struct {
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Country string  `json:"country"`
+	State   string  `json:"state"`
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "openweathermap",
+		Description: "OpenWeatherMap One Call API (requires openweathermap_api_key)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &openWeatherMapProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily},
+	})
+}
+
+// openWeatherMapProvider is the implementation of WeatherProvider that uses the OpenWeatherMap One Call API.
+type openWeatherMapProvider struct {
+	cache *Cache
+}
+
+// GetWeather fetches weather forecast data from OpenWeatherMap for the location in the config.
+// It uses the openweathermap_api_key from the config, both for the forecast and to
+// resolve place names through the OpenWeatherMap geocoding API.
+func (p *openWeatherMapProvider) GetWeather(c *Config) (*Weather, error) {
+	if c.OpenWeatherMapAPIKey == "" {
+		return nil, fmt.Errorf("openweathermap_api_key is not set in the config")
+	}
+
+	key := cacheKey("openweathermap", c.Location)
+	if c.Lang != "" {
+		key += "|" + c.Lang
+	}
+
+	// Check cache first
+	if !c.NoCache {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
+			return entry.Weather, nil
+		}
+	}
+
+	location, err := p.resolveLocation(c.Location, c.OpenWeatherMapAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("lat", strconv.FormatFloat(location.Lat, 'f', -1, 64))
+	query.Set("lon", strconv.FormatFloat(location.Lon, 'f', -1, 64))
+	query.Set("appid", c.OpenWeatherMapAPIKey)
+	query.Set("units", "metric")
+	query.Set("exclude", "minutely,alerts")
+	if c.Lang != "" {
+		query.Set("lang", c.Lang)
+	}
+
+	var forecast OWMResponse
+	if err := fetchJSON(owmOneCallURL+"?"+query.Encode(), &forecast); err != nil {
+		return nil, err
+	}
+
+	weather := forecast.toWeather(location)
+
+	// Save to cache
+	if err := p.cache.Set(key, weather); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *openWeatherMapProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// resolveLocation turns a configured location into coordinates using the OpenWeatherMap geocoding API.
+func (p *openWeatherMapProvider) resolveLocation(location string, apiKey string) (WeatherLocation, error) {
+	if lat, lon, ok := parseCoordinates(location); ok {
+		return WeatherLocation{Name: location, Lat: lat, Lon: lon}, nil
+	}
+
+	query := url.Values{}
+	query.Set("q", location)
+	query.Set("limit", "1")
+	query.Set("appid", apiKey)
+
+	var results []OWMGeocodingResult
+	if err := fetchJSON(owmGeocodingURL+"?"+query.Encode(), &results); err != nil {
+		return WeatherLocation{}, err
+	}
+	if len(results) == 0 {
+		return WeatherLocation{}, fmt.Errorf("location %q not found", location)
+	}
+
+	return WeatherLocation{
+		Name:    results[0].Name,
+		Region:  results[0].State,
+		Country: results[0].Country,
+		Lat:     results[0].Lat,
+		Lon:     results[0].Lon,
+	}, nil
+}
+
+// toWeather maps the OWMResponse to the provider-neutral Weather struct.
+func (r *OWMResponse) toWeather(location WeatherLocation) *Weather {
+	zone := time.FixedZone(r.Timezone, r.TimezoneOffset)
+	location.TzID = r.Timezone
+	location.LocaltimeEpoch = r.Current.Dt
+
+	var hourlyForecasts []HourlyForecast
+	for _, hour := range r.Hourly {
+		condition := owmCondition(hour.Weather)
+		hourlyForecasts = append(hourlyForecasts, HourlyForecast{
+			TimeEpoch:    hour.Dt,
+			Emoji:        getEmojiForOWMCode(condition.ID),
+			Condition:    condition.Description,
+			IsDay:        isOWMDayIcon(condition.Icon),
+			TempC:        hour.Temp,
+			FeelslikeC:   hour.FeelsLike,
+			Humidity:     hour.Humidity,
+			WindKph:      msToKph(hour.WindSpeed),
+			WindDegree:   hour.WindDeg,
+			WindDir:      windDirection(hour.WindDeg),
+			GustKph:      msToKph(hour.WindGust),
+			PressureMb:   hour.Pressure,
+			PrecipMm:     hour.Rain.OneHour + hour.Snow.OneHour,
+			Cloud:        hour.Clouds,
+			DewpointC:    hour.DewPoint,
+			ChanceOfRain: int(math.Round(hour.Pop * 100)),
+			VisKm:        hour.Visibility / 1000,
+			Uv:           hour.Uvi,
+		})
+	}
+
+	var dailyForecasts []DailyForecast
+	for _, day := range r.Daily {
+		condition := owmCondition(day.Weather)
+		dailyForecasts = append(dailyForecasts, DailyForecast{
+			DateEpoch:     day.Dt,
+			Date:          time.Unix(day.Dt, 0).In(zone).Format("2006-01-02"),
+			Emoji:         getEmojiForOWMCode(condition.ID),
+			Condition:     condition.Description,
+			MaxtempC:      day.Temp.Max,
+			MintempC:      day.Temp.Min,
+			AvgtempC:      (day.Temp.Morn + day.Temp.Day + day.Temp.Eve + day.Temp.Night) / 4,
+			MaxwindKph:    msToKph(day.WindSpeed),
+			TotalprecipMm: day.Rain + day.Snow,
+			TotalsnowCm:   day.Snow / 10,
+			Avghumidity:   day.Humidity,
+			ChanceOfRain:  int(math.Round(day.Pop * 100)),
+			Uv:            day.Uvi,
+			Astro: WeatherAstro{
+				SunriseEpoch:     day.Sunrise,
+				SunsetEpoch:      day.Sunset,
+				MoonriseEpoch:    day.Moonrise,
+				MoonsetEpoch:     day.Moonset,
+				MoonPhase:        owmMoonPhaseName(day.MoonPhase),
+				MoonIllumination: int(math.Round((1 - math.Cos(2*math.Pi*day.MoonPhase)) / 2 * 100)),
+			},
+		})
+	}
+
+	condition := owmCondition(r.Current.Weather)
+	return &Weather{
+		Location: location,
+		Current: WeatherCurrent{
+			Location:         location.Name,
+			Country:          location.Country,
+			LastUpdatedEpoch: r.Current.Dt,
+			Emoji:            getEmojiForOWMCode(condition.ID),
+			Condition:        condition.Description,
+			IsDay:            r.Current.Dt >= r.Current.Sunrise && r.Current.Dt < r.Current.Sunset,
+			TempC:            r.Current.Temp,
+			FeelslikeC:       r.Current.FeelsLike,
+			Humidity:         r.Current.Humidity,
+			WindKph:          msToKph(r.Current.WindSpeed),
+			WindDegree:       r.Current.WindDeg,
+			WindDir:          windDirection(r.Current.WindDeg),
+			GustKph:          msToKph(r.Current.WindGust),
+			PressureMb:       r.Current.Pressure,
+			PrecipMm:         r.Current.Rain.OneHour + r.Current.Snow.OneHour,
+			Cloud:            r.Current.Clouds,
+			DewpointC:        r.Current.DewPoint,
+			VisKm:            r.Current.Visibility / 1000,
+			Uv:               r.Current.Uvi,
+		},
+		HourlyForecast: hourlyForecasts,
+		DailyForecast:  dailyForecasts,
+		Source: WeatherSource{
+			Provider:    "openweathermap",
+			Attribution: "Weather data provided by OpenWeather",
+			URL:         "https://openweathermap.org/",
+		},
+	}
+}
+
+// owmCondition returns the primary weather condition of an OpenWeatherMap entry.
+func owmCondition(conditions []OWMCondition) OWMCondition {
+	if len(conditions) == 0 {
+		return OWMCondition{}
+	}
+	return conditions[0]
+}
+
+// isOWMDayIcon reports whether an OpenWeatherMap icon id (e.g. "01d") is a day icon.
+func isOWMDayIcon(icon string) bool {
+	return len(icon) > 0 && icon[len(icon)-1] == 'd'
+}
+
+// owmMoonPhaseName converts the OpenWeatherMap moon phase fraction (0 and 1 are new moon,
+// 0.5 is full moon) to the phase names used by weatherapi.com.
+func owmMoonPhaseName(phase float64) string {
+	switch {
+	case phase < 0.03 || phase > 0.97:
+		return "New Moon"
+	case phase < 0.22:
+		return "Waxing Crescent"
+	case phase < 0.28:
+		return "First Quarter"
+	case phase < 0.47:
+		return "Waxing Gibbous"
+	case phase < 0.53:
+		return "Full Moon"
+	case phase < 0.72:
+		return "Waning Gibbous"
+	case phase < 0.78:
+		return "Last Quarter"
+	default:
+		return "Waning Crescent"
+	}
+}
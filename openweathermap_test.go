@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenWeatherMapProvider_GetWeather(t *testing.T) {
+
+	// Create a mock HTTP server for both the geocoding and the One Call endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "test_owm_key" {
+			t.Errorf("Expected query parameter 'appid' to be 'test_owm_key', got: %s", r.URL.Query().Get("appid"))
+		}
+		switch r.URL.Path {
+		case "/geo/1.0/direct":
+			w.Write([]byte(`[{"name":"Athens","lat":37.98,"lon":23.72,"country":"GR","state":"Attica"}]`))
+		case "/data/3.0/onecall":
+			if r.URL.Query().Get("units") != "metric" {
+				t.Errorf("Expected query parameter 'units' to be 'metric', got: %s", r.URL.Query().Get("units"))
+			}
+			w.Write([]byte(`{
+				"timezone": "Europe/Athens", "timezone_offset": 7200,
+				"current": {"dt": 1736705400, "sunrise": 1736660000, "sunset": 1736696000, "temp": 12.5, "feels_like": 11.8,
+					"pressure": 1021, "humidity": 66, "uvi": 0, "clouds": 20, "wind_speed": 5, "wind_deg": 0,
+					"weather": [{"id": 801, "main": "Clouds", "description": "few clouds", "icon": "02n"}]},
+				"hourly": [{"dt": 1736708400, "temp": 11.9, "feels_like": 11.1, "humidity": 70, "wind_speed": 4, "wind_deg": 90,
+					"pop": 0.35, "rain": {"1h": 0.4}, "weather": [{"id": 500, "description": "light rain", "icon": "10n"}]}],
+				"daily": [{"dt": 1736676000, "sunrise": 1736660000, "sunset": 1736696000, "moon_phase": 0.5,
+					"temp": {"min": 8.1, "max": 15.3, "morn": 9, "day": 14, "eve": 12, "night": 9},
+					"weather": [{"id": 615, "description": "light rain and snow", "icon": "13d"}]}]
+			}`))
+		default:
+			t.Errorf("Unexpected request to: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	originalURL, originalGeocodingURL := owmOneCallURL, owmGeocodingURL
+	owmOneCallURL = server.URL + "/data/3.0/onecall"
+	owmGeocodingURL = server.URL + "/geo/1.0/direct"
+	defer func() { owmOneCallURL, owmGeocodingURL = originalURL, originalGeocodingURL }()
+
+	tempDir, err := os.MkdirTemp("", "cache-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "cache.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider := &openWeatherMapProvider{cache: cache}
+
+	_, err = provider.GetWeather(&Config{Location: "Athens"})
+	assert.EqualError(t, err, "openweathermap_api_key is not set in the config")
+
+	weather, err := provider.GetWeather(&Config{Location: "Athens", OpenWeatherMapAPIKey: "test_owm_key"})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	// Assertions
+	assert.Equal(t, "Athens", weather.Current.Location)
+	assert.Equal(t, "GR", weather.Current.Country)
+	assert.Equal(t, 12.5, weather.Current.TempC)
+	assert.Equal(t, 18.0, weather.Current.WindKph)
+	assert.Equal(t, "N", weather.Current.WindDir)
+	assert.Equal(t, "🌤️", weather.Current.Emoji)
+	assert.False(t, weather.Current.IsDay)
+	assert.Len(t, weather.HourlyForecast, 1)
+	assert.Equal(t, "🌦️", weather.HourlyForecast[0].Emoji)
+	assert.Equal(t, 35, weather.HourlyForecast[0].ChanceOfRain)
+	assert.Equal(t, 0.4, weather.HourlyForecast[0].PrecipMm)
+	assert.Len(t, weather.DailyForecast, 1)
+	assert.Equal(t, "❄️", weather.DailyForecast[0].Emoji)
+	assert.Equal(t, "Full Moon", weather.DailyForecast[0].Astro.MoonPhase)
+	assert.Equal(t, 100, weather.DailyForecast[0].Astro.MoonIllumination)
+	assert.Equal(t, "openweathermap", weather.Source.Provider)
+}
+
+func TestGetEmojiForOWMCode(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected string
+	}{
+		{800, "☀️"},
+		{622, "❄️"},
+		// Every drizzle code falls back to the drizzle group
+		{300, "🌦️"},
+		{301, "🌦️"},
+		{311, "🌦️"},
+		{321, "🌦️"},
+		{701, "🌫️"},
+		{799, "🌫️"},
+		{100, "❓"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, getEmojiForOWMCode(tt.code), "code %d", tt.code)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// captureFileName is the name of the file holding a recording inside the record directory.
+const captureFileName = "capture.json"
+
+// secretQueryParams are the query parameters that are redacted from recorded URLs,
+// so a capture can be attached to a bug report without leaking API keys.
+var secretQueryParams = []string{"key", "appid", "apikey", "api_key", "token"}
+
+// Capture is a recording of the API responses of a wayther run.
+// It keeps the options of the run that change the requests, so it replays whatever the options of the replaying run.
+type Capture struct {
+	RecordedAt    time.Time          `json:"recorded_at"`
+	Location      string             `json:"location"`
+	Providers     []string           `json:"providers"`
+	ForecastHours int                `json:"forecast_hours"`
+	ForecastDays  int                `json:"forecast_days,omitempty"`
+	Lang          string             `json:"lang,omitempty"`
+	AQI           bool               `json:"aqi,omitempty"`
+	Alerts        bool               `json:"alerts,omitempty"`
+	Marine        bool               `json:"marine,omitempty"`
+	Exchanges     []RecordedExchange `json:"exchanges"`
+}
+
+// RecordedExchange is a single recorded HTTP request and its raw response.
+type RecordedExchange struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// LoadCapture reads the capture stored in the given directory.
+func LoadCapture(dir string) (*Capture, error) {
+	data, err := os.ReadFile(filepath.Join(dir, captureFileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+	capture := &Capture{}
+	if err := json.Unmarshal(data, capture); err != nil {
+		return nil, fmt.Errorf("failed to decode capture: %w", err)
+	}
+	return capture, nil
+}
+
+// save writes the capture to the given directory.
+func (c *Capture) save(dir string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, captureFileName), data, 0644)
+}
+
+// redactURL removes the secret query parameters from a URL.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	for _, param := range secretQueryParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// recordingTransport is an http.RoundTripper that saves every response to a capture.
+type recordingTransport struct {
+	mu      sync.Mutex
+	dir     string
+	capture *Capture
+	next    http.RoundTripper
+}
+
+// RoundTrip performs the request and records the response before handing it back.
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.capture.Exchanges = append(t.capture.Exchanges, RecordedExchange{
+		Method: req.Method,
+		URL:    redactURL(req.URL.String()),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   string(body),
+	})
+	if err := t.capture.save(t.dir); err != nil {
+		return nil, fmt.Errorf("failed to save capture: %w", err)
+	}
+	return resp, nil
+}
+
+// startRecording records all the API responses of this run to the record directory of the config.
+// The cache is bypassed so that every response is actually fetched. The returned function
+// stops the recording.
+func startRecording(c *Config, now time.Time) (func(), error) {
+	c.NoCache = true
+	capture := &Capture{
+		RecordedAt:    now,
+		Location:      c.Location,
+		Providers:     c.ProviderChain(),
+		ForecastHours: c.ForecastHours,
+		ForecastDays:  c.ForecastDays,
+		Lang:          c.Lang,
+		AQI:           c.AQI,
+		Alerts:        c.Alerts,
+		Marine:        c.Marine,
+	}
+	if err := capture.save(c.RecordDir); err != nil {
+		return nil, fmt.Errorf("failed to start recording: %w", err)
+	}
+
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	original := httpClient.Transport
+	httpClient.Transport = &recordingTransport{dir: c.RecordDir, capture: capture, next: next}
+	return func() { httpClient.Transport = original }, nil
+}
+
+// replayTransport is an http.RoundTripper that answers requests from a capture.
+type replayTransport struct {
+	capture *Capture
+}
+
+// RoundTrip returns the recorded response matching the method and URL of the request.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestURL := redactURL(req.URL.String())
+	for _, exchange := range t.capture.Exchanges {
+		if exchange.Method != req.Method || exchange.URL != requestURL {
+			continue
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+			StatusCode:    exchange.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        exchange.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(exchange.Body))),
+			ContentLength: int64(len(exchange.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded response for %s %s", req.Method, requestURL)
+}
+
+// replayClock returns a clock fixed at the time the capture was recorded, when the config replays one.
+func replayClock(c *Config) (func() time.Time, bool, error) {
+	if c.ReplayDir == "" || !slices.Contains(c.ProviderChain(), "replay") {
+		return nil, false, nil
+	}
+	capture, err := LoadCapture(c.ReplayDir)
+	if err != nil {
+		return nil, false, err
+	}
+	return func() time.Time { return capture.RecordedAt }, true, nil
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "replay",
+		Description: "Replays the API responses recorded with --record (see replay_dir)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &replayProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily},
+	})
+}
+
+// replayProvider is the implementation of WeatherProvider that serves a recorded capture.
+// It runs the providers of the recording against the recorded responses instead of the network.
+type replayProvider struct {
+	cache *Cache
+}
+
+// GetWeather replays the capture in the replay directory of the config.
+func (p *replayProvider) GetWeather(c *Config) (*Weather, error) {
+	if c.ReplayDir == "" {
+		return nil, fmt.Errorf("replay_dir is not set in the config")
+	}
+	capture, err := LoadCapture(c.ReplayDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(capture.Providers) == 0 || slices.Contains(capture.Providers, "replay") {
+		return nil, fmt.Errorf("capture in %s does not name the providers to replay", c.ReplayDir)
+	}
+
+	replayed := *c
+	replayed.Location = capture.Location
+	replayed.Provider = capture.Providers[0]
+	replayed.Providers = capture.Providers
+	replayed.ForecastHours = capture.ForecastHours
+	replayed.ForecastDays = capture.ForecastDays
+	replayed.Lang = capture.Lang
+	replayed.AQI = capture.AQI
+	replayed.Alerts = capture.Alerts
+	replayed.Marine = capture.Marine
+	replayed.NoCache = true
+	replayed.RecordDir = ""
+
+	original := httpClient.Transport
+	httpClient.Transport = &replayTransport{capture: capture}
+	defer func() { httpClient.Transport = original }()
+
+	// The recorded providers get a memory cache, so replayed data never ends up in the real cache
+	return (&configuredProvider{cache: newMemoryCache()}).GetWeather(&replayed)
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *replayProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
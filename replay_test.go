@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "https://api.weatherapi.com/v1/forecast.json?days=2&key=REDACTED&q=London",
+		redactURL("https://api.weatherapi.com/v1/forecast.json?key=secret&q=London&days=2"))
+	assert.Equal(t, "https://api.met.no/compact?lat=59.9&lon=10.7", redactURL("https://api.met.no/compact?lat=59.9&lon=10.7"))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	responseJSON, err := os.ReadFile("samples/response.json")
+	if err != nil {
+		t.Fatalf("Failed to read response.json: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(responseJSON)
+	}))
+
+	originalURL := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = originalURL }()
+
+	recordDir, err := os.MkdirTemp("", "record-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(recordDir)
+
+	recordedAt := time.Date(2025, 8, 16, 20, 0, 0, 0, time.UTC)
+
+	// Record a run against the mock server
+	config := &Config{APIKey: "secret", Location: "London", Provider: "weatherapi", RecordDir: recordDir}
+	stopRecording, err := startRecording(config, recordedAt)
+	if err != nil {
+		t.Fatalf("startRecording returned an error: %v", err)
+	}
+	assert.True(t, config.NoCache, "Recording should bypass the cache")
+
+	recorded, err := (&configuredProvider{cache: newMemoryCache()}).GetWeather(config)
+	stopRecording()
+	if err != nil {
+		t.Fatalf("GetWeather returned an error while recording: %v", err)
+	}
+
+	capture, err := LoadCapture(recordDir)
+	if err != nil {
+		t.Fatalf("LoadCapture returned an error: %v", err)
+	}
+	assert.Equal(t, recordedAt, capture.RecordedAt.UTC())
+	assert.Equal(t, "London", capture.Location)
+	assert.Equal(t, []string{"weatherapi"}, capture.Providers)
+	if assert.Len(t, capture.Exchanges, 1) {
+		assert.Contains(t, capture.Exchanges[0].URL, "key=REDACTED")
+		assert.NotContains(t, capture.Exchanges[0].URL, "secret")
+		assert.Equal(t, http.StatusOK, capture.Exchanges[0].Status)
+	}
+
+	// Replay it without the server, with another key and location in the config
+	server.Close()
+
+	replayConfig := &Config{APIKey: "other", Location: "Paris", Provider: "replay", ReplayDir: recordDir}
+	replayed, err := NewWeather(&configuredProvider{cache: newMemoryCache()}, replayConfig)
+	if err != nil {
+		t.Fatalf("GetWeather returned an error while replaying: %v", err)
+	}
+	assert.Equal(t, recorded, replayed)
+
+	clock, replaying, err := replayClock(replayConfig)
+	assert.NoError(t, err)
+	assert.True(t, replaying)
+	assert.Equal(t, recordedAt, clock().UTC())
+
+	// Requests that were not recorded fail
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/other?key=secret", nil)
+	_, err = (&replayTransport{capture: capture}).RoundTrip(req)
+	assert.EqualError(t, err, "no recorded response for GET "+server.URL+"/other?key=REDACTED")
+
+	// Replaying needs a capture
+	_, err = (&replayProvider{}).GetWeather(&Config{ReplayDir: t.TempDir()})
+	assert.ErrorContains(t, err, "failed to read capture")
+}
+
+func TestReplayWithOtherOptions(t *testing.T) {
+	responseJSON, err := os.ReadFile("samples/response.json")
+	if err != nil {
+		t.Fatalf("Failed to read response.json: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(responseJSON)
+	}))
+
+	originalURL := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = originalURL }()
+
+	// Record a run whose options change the request URL
+	recordDir := t.TempDir()
+	config := &Config{APIKey: "secret", Location: "London", Provider: "weatherapi", RecordDir: recordDir,
+		ForecastHours: 72, AQI: true, Lang: "de"}
+	stopRecording, err := startRecording(config, time.Now())
+	if err != nil {
+		t.Fatalf("startRecording returned an error: %v", err)
+	}
+	_, err = (&configuredProvider{cache: newMemoryCache()}).GetWeather(config)
+	stopRecording()
+	if err != nil {
+		t.Fatalf("GetWeather returned an error while recording: %v", err)
+	}
+	server.Close()
+
+	capture, err := LoadCapture(recordDir)
+	if err != nil {
+		t.Fatalf("LoadCapture returned an error: %v", err)
+	}
+	assert.Equal(t, 72, capture.ForecastHours)
+	assert.True(t, capture.AQI)
+	assert.Equal(t, "de", capture.Lang)
+
+	// Replaying with the default options requests the recorded URL
+	replayConfig := &Config{Location: "Paris", Provider: "replay", ReplayDir: recordDir, ForecastHours: 23}
+	_, err = NewWeather(&configuredProvider{cache: newMemoryCache()}, replayConfig)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// stationMaxAge is how old the station readings may be before the station provider stops serving them.
+const stationMaxAge = 30 * time.Minute
+
+// StationReadings holds the latest observations uploaded by a personal weather station,
+// converted to metric units. Readings the station did not send are left nil.
+type StationReadings struct {
+	Timestamp   time.Time `json:"timestamp"`
+	StationType string    `json:"station_type,omitempty"`
+	TempC       *float64  `json:"temp_c,omitempty"`
+	FeelslikeC  *float64  `json:"feelslike_c,omitempty"`
+	Humidity    *float64  `json:"humidity,omitempty"`
+	WindKph     *float64  `json:"wind_kph,omitempty"`
+	WindDegree  *float64  `json:"wind_degree,omitempty"`
+	PressureMb  *float64  `json:"pressure_mb,omitempty"`
+	PrecipMm    *float64  `json:"precip_mm,omitempty"`
+	Uv          *float64  `json:"uv,omitempty"`
+}
+
+// parseStationUpload parses the form values of an Ecowitt or Weather Underground upload.
+// Both protocols send imperial units with mostly the same field names.
+func parseStationUpload(values url.Values, now time.Time) (*StationReadings, error) {
+	readings := &StationReadings{
+		Timestamp:   now,
+		StationType: values.Get("stationtype"),
+		TempC:       convertReading(values, fahrenheitToCelsius, "tempf"),
+		FeelslikeC:  convertReading(values, fahrenheitToCelsius, "feelslikef", "windchillf", "heatindexf"),
+		Humidity:    convertReading(values, nil, "humidity"),
+		WindKph:     convertReading(values, mphToKph, "windspeedmph"),
+		WindDegree:  convertReading(values, nil, "winddir"),
+		PressureMb:  convertReading(values, inHgToMb, "baromrelin", "baromin"),
+		PrecipMm:    convertReading(values, inchesToMm, "dailyrainin"),
+		Uv:          convertReading(values, nil, "UV", "uv"),
+	}
+	if readings.StationType == "" {
+		readings.StationType = values.Get("softwaretype")
+	}
+	if readings.FeelslikeC == nil {
+		readings.FeelslikeC = readings.TempC
+	}
+
+	if readings.TempC == nil && readings.Humidity == nil && readings.WindKph == nil && readings.PressureMb == nil {
+		return nil, fmt.Errorf("no weather readings in upload")
+	}
+	return readings, nil
+}
+
+// convertReading returns the first of the given form fields holding a number, converted with convert.
+// Stations report missing sensors as -9999, which is treated as absent.
+func convertReading(values url.Values, convert func(float64) float64, keys ...string) *float64 {
+	for _, key := range keys {
+		value, err := strconv.ParseFloat(values.Get(key), 64)
+		if err != nil || value <= -9999 {
+			continue
+		}
+		if convert != nil {
+			value = convert(value)
+		}
+		return &value
+	}
+	return nil
+}
+
+// apply overrides the fields of current with the readings the station sent.
+func (r *StationReadings) apply(current WeatherCurrent) WeatherCurrent {
+	current.LastUpdatedEpoch = r.Timestamp.Unix()
+	if r.TempC != nil {
+		current.TempC = *r.TempC
+	}
+	if r.FeelslikeC != nil {
+		current.FeelslikeC = *r.FeelslikeC
+	}
+	if r.Humidity != nil {
+		current.Humidity = int(*r.Humidity)
+	}
+	if r.WindKph != nil {
+		current.WindKph = *r.WindKph
+	}
+	if r.WindDegree != nil {
+		current.WindDegree = int(*r.WindDegree)
+		current.WindDir = windDirection(current.WindDegree)
+	}
+	if r.PressureMb != nil {
+		current.PressureMb = *r.PressureMb
+	}
+	if r.PrecipMm != nil {
+		current.PrecipMm = *r.PrecipMm
+	}
+	if r.Uv != nil {
+		current.Uv = *r.Uv
+	}
+	return current
+}
+
+// StationStore keeps the latest station readings in station.json, next to the cache.
+// It is also the HTTP handler receiving the station uploads.
+type StationStore struct {
+	filePath string
+}
+
+// NewStationStore creates a StationStore in the given directory.
+func NewStationStore(dir string) *StationStore {
+	return &StationStore{filePath: filepath.Join(dir, "station.json")}
+}
+
+// Load reads the latest station readings from disk.
+func (s *StationStore) Load() (*StationReadings, error) {
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		return nil, err
+	}
+	readings := &StationReadings{}
+	if err := json.Unmarshal(data, readings); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+
+// Save writes the station readings to disk as a JSON file. The file is written under a temporary
+// name and then renamed into place, so the station provider never reads a partly written file.
+func (s *StationStore) Save(readings *StationReadings) error {
+	data, err := json.MarshalIndent(readings, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dir, filepath.Base(s.filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name()) // no-op once renamed
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Chmod(0644); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), s.filePath)
+}
+
+// ServeHTTP accepts Ecowitt uploads (form POST) and Weather Underground uploads
+// (GET /weatherstation/updateweatherstation.php) on any path and stores the readings.
+func (s *StationStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	readings, err := parseStationUpload(r.Form, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.Save(readings); err != nil {
+		log.Printf("Failed to save station readings: %v", err)
+		http.Error(w, "failed to save readings", http.StatusInternalServerError)
+		return
+	}
+
+	// Weather Underground clients expect this exact body
+	fmt.Fprintln(w, "success")
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "station",
+		Description: "Local weather station readings, forecast from station_forecast_provider",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			// The readings are stored next to the cache file, which a memory cache does not have
+			if cache == nil || cache.filePath == "" {
+				return nil, fmt.Errorf("the station provider needs the cache directory to find the station readings")
+			}
+			return &stationProvider{cache: cache, store: NewStationStore(filepath.Dir(cache.filePath))}, nil
+		},
+		Capabilities:     []Capability{CapabilityHourly, CapabilityDaily},
+		CapabilitiesFrom: stationForecastProviderName,
+	})
+}
+
+// stationForecastProviderName returns the name of the provider the station forecast comes from.
+func stationForecastProviderName(c *Config) string {
+	if c.StationForecastProvider == "" {
+		return defaultProvider
+	}
+	return c.StationForecastProvider
+}
+
+// stationProvider is the implementation of WeatherProvider that serves the current conditions
+// measured by a local weather station and the forecast of another provider.
+type stationProvider struct {
+	cache *Cache
+	store *StationStore
+}
+
+// GetWeather fetches the forecast from the configured forecast provider and replaces
+// its current conditions with the latest station readings.
+func (p *stationProvider) GetWeather(c *Config) (*Weather, error) {
+	readings, err := p.store.Load()
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no weather station readings received yet, see 'wayther station listen'")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weather station readings: %w", err)
+	}
+	if time.Since(readings.Timestamp) > stationMaxAge {
+		return nil, fmt.Errorf("weather station readings are older than %s", stationMaxAge)
+	}
+
+	name := stationForecastProviderName(c)
+	if name == "station" {
+		return nil, fmt.Errorf("station_forecast_provider cannot be the station provider itself")
+	}
+	forecastProvider, err := NewWeatherProvider(name, c, p.cache)
+	if err != nil {
+		return nil, err
+	}
+	forecast, err := forecastProvider.GetWeather(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the forecast from %s: %w", name, err)
+	}
+
+	// Copy the forecast, so the cached weather of the forecast provider is left untouched
+	weather := *forecast
+	weather.Current = readings.apply(forecast.Current)
+	weather.Source.Provider = "station"
+
+	return &weather, nil
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *stationProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
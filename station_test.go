@@ -0,0 +1,222 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStationUpload(t *testing.T) {
+	now := time.Date(2025, 1, 12, 18, 0, 0, 0, time.UTC)
+
+	t.Run("Weather Underground", func(t *testing.T) {
+		values, _ := url.ParseQuery("ID=KXX1&PASSWORD=secret&dateutc=now&tempf=50&humidity=80&windspeedmph=10&winddir=270&baromin=29.92&dailyrainin=0.1&uv=-9999&softwaretype=WS-2902")
+
+		readings, err := parseStationUpload(values, now)
+		if err != nil {
+			t.Fatalf("parseStationUpload returned an error: %v", err)
+		}
+
+		assert.Equal(t, now, readings.Timestamp)
+		assert.Equal(t, "WS-2902", readings.StationType)
+		assert.Equal(t, 10.0, *readings.TempC)
+		assert.Equal(t, 10.0, *readings.FeelslikeC)
+		assert.Equal(t, 80.0, *readings.Humidity)
+		assert.Equal(t, 16.1, *readings.WindKph)
+		assert.Equal(t, 270.0, *readings.WindDegree)
+		assert.Equal(t, 1013.2, *readings.PressureMb)
+		assert.Equal(t, 2.5, *readings.PrecipMm)
+		assert.Nil(t, readings.Uv)
+
+		// Weather Underground names the UV index in upper case
+		values.Set("UV", "4")
+		readings, err = parseStationUpload(values, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 4.0, *readings.Uv)
+	})
+
+	t.Run("Ecowitt", func(t *testing.T) {
+		values, _ := url.ParseQuery("PASSKEY=ABC&stationtype=EasyWeatherPro_V5.1.1&tempf=68&feelslikef=70&humidity=40&baromrelin=30.00&uv=3")
+
+		readings, err := parseStationUpload(values, now)
+		if err != nil {
+			t.Fatalf("parseStationUpload returned an error: %v", err)
+		}
+
+		assert.Equal(t, "EasyWeatherPro_V5.1.1", readings.StationType)
+		assert.Equal(t, 20.0, *readings.TempC)
+		assert.Equal(t, 21.1, *readings.FeelslikeC)
+		assert.Equal(t, 1015.9, *readings.PressureMb)
+		assert.Equal(t, 3.0, *readings.Uv)
+		assert.Nil(t, readings.WindKph)
+	})
+
+	t.Run("No readings", func(t *testing.T) {
+		_, err := parseStationUpload(url.Values{"ID": {"KXX1"}}, now)
+		assert.EqualError(t, err, "no weather readings in upload")
+	})
+}
+
+func TestStationStore_ServeHTTP(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "station-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	store := NewStationStore(tempDir)
+
+	// Ecowitt posts a form
+	req := httptest.NewRequest(http.MethodPost, "/data/report/", strings.NewReader("PASSKEY=ABC&tempf=68&humidity=40"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	store.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	readings, err := store.Load()
+	if err != nil {
+		t.Fatalf("Failed to load the readings: %v", err)
+	}
+	assert.Equal(t, 20.0, *readings.TempC)
+
+	// Weather Underground uses a GET request
+	req = httptest.NewRequest(http.MethodGet, "/weatherstation/updateweatherstation.php?ID=KXX1&tempf=50", nil)
+	rec = httptest.NewRecorder()
+	store.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "success\n", rec.Body.String())
+
+	readings, _ = store.Load()
+	assert.Equal(t, 10.0, *readings.TempC)
+
+	// Uploads without readings are rejected
+	req = httptest.NewRequest(http.MethodGet, "/weatherstation/updateweatherstation.php?ID=KXX1", nil)
+	rec = httptest.NewRecorder()
+	store.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestStationStore_SaveWhileLoading(t *testing.T) {
+	tempDir := t.TempDir()
+	store := NewStationStore(tempDir)
+	temp := 12.5
+	if err := store.Save(&StationReadings{Timestamp: time.Now(), TempC: &temp}); err != nil {
+		t.Fatalf("Failed to save the readings: %v", err)
+	}
+
+	// The readings are replaced at once, so a concurrent load never sees a partly written file
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			store.Save(&StationReadings{Timestamp: time.Now(), TempC: &temp})
+		}
+	}()
+	for loading := true; loading; {
+		select {
+		case <-done:
+			loading = false
+		default:
+			_, err := store.Load()
+			assert.NoError(t, err)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary file should be left behind")
+}
+
+func TestStationProvider_GetWeather(t *testing.T) {
+	mockResponse := loadMockResponse(t)
+
+	RegisterProvider(ProviderInfo{
+		Name: "mock",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &MockWeatherProvider{mockResponse: mockResponse}, nil
+		},
+	})
+	defer delete(providerRegistry, "mock")
+
+	tempDir, err := os.MkdirTemp("", "station-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cache, err := NewCache(filepath.Join(tempDir, "config.json"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	provider, err := NewWeatherProvider("station", &Config{}, cache)
+	if err != nil {
+		t.Fatalf("Failed to create the station provider: %v", err)
+	}
+	config := &Config{StationForecastProvider: "mock"}
+
+	// Nothing was uploaded yet
+	_, err = provider.GetWeather(config)
+	assert.ErrorContains(t, err, "no weather station readings received yet")
+
+	store := NewStationStore(tempDir)
+	temp, humidity := 12.3, 55.0
+	if err := store.Save(&StationReadings{Timestamp: time.Now(), TempC: &temp, Humidity: &humidity}); err != nil {
+		t.Fatalf("Failed to save the readings: %v", err)
+	}
+
+	weather, err := provider.GetWeather(config)
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	forecast := mockResponse.toWeather()
+	assert.Equal(t, "station", weather.Source.Provider)
+	assert.Equal(t, 12.3, weather.Current.TempC)
+	assert.Equal(t, 55, weather.Current.Humidity)
+	// Readings the station did not send come from the forecast provider
+	assert.Equal(t, forecast.Current.WindKph, weather.Current.WindKph)
+	assert.Equal(t, forecast.Current.Emoji, weather.Current.Emoji)
+	assert.Equal(t, forecast.HourlyForecast, weather.HourlyForecast)
+
+	// Old readings are not served
+	if err := store.Save(&StationReadings{Timestamp: time.Now().Add(-time.Hour), TempC: &temp}); err != nil {
+		t.Fatalf("Failed to save the readings: %v", err)
+	}
+	_, err = provider.GetWeather(config)
+	assert.EqualError(t, err, "weather station readings are older than 30m0s")
+
+	// The station cannot forecast for itself
+	store.Save(&StationReadings{Timestamp: time.Now(), TempC: &temp})
+	_, err = provider.GetWeather(&Config{StationForecastProvider: "station"})
+	assert.EqualError(t, err, "station_forecast_provider cannot be the station provider itself")
+}
+
+func TestStationProvider_Capabilities(t *testing.T) {
+	info, err := LookupProvider("station")
+	if err != nil {
+		t.Fatalf("LookupProvider returned an error: %v", err)
+	}
+
+	// The forecast capabilities are the ones of the forecast provider
+	assert.True(t, info.SupportsWith(&Config{}, CapabilityDaily))
+	assert.True(t, info.SupportsWith(&Config{StationForecastProvider: "nws"}, CapabilityHourly))
+	assert.False(t, info.SupportsWith(&Config{StationForecastProvider: "nws"}, CapabilityDaily))
+	assert.False(t, info.SupportsWith(&Config{StationForecastProvider: "station"}, CapabilityHourly))
+	assert.False(t, info.SupportsWith(&Config{StationForecastProvider: "nope"}, CapabilityHourly))
+
+	assert.EqualError(t, checkProviderCapabilities(&cobra.Command{}, &Config{Provider: "station", StationForecastProvider: "nws", ForecastDays: 3}),
+		"weather provider \"station\" does not support daily data (--forecast-days)")
+
+	// The readings are only looked up next to a cache file
+	_, err = NewWeatherProvider("station", &Config{}, newMemoryCache())
+	assert.EqualError(t, err, "the station provider needs the cache directory to find the station readings")
+}
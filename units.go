@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Units holds the unit each quantity is displayed in by the unit-aware template fields.
+// The weather data itself is always kept in metric units.
+type Units struct {
+	temp     string // c or f
+	wind     string // kph, mph, ms or kn
+	pressure string // mb, hpa or inhg
+	precip   string // mm or in
+	distance string // km or mi
+}
+
+// unitSystems are the predefined unit systems of the units config key.
+var unitSystems = map[string]Units{
+	"metric":    {temp: "c", wind: "kph", pressure: "mb", precip: "mm", distance: "km"},
+	"imperial":  {temp: "f", wind: "mph", pressure: "inhg", precip: "in", distance: "mi"},
+	"uk-hybrid": {temp: "c", wind: "mph", pressure: "mb", precip: "mm", distance: "mi"},
+}
+
+// unitChoices lists the units each quantity can be overridden with.
+var unitChoices = map[string][]string{
+	"temp":     {"c", "f"},
+	"wind":     {"kph", "mph", "ms", "kn"},
+	"pressure": {"mb", "hpa", "inhg"},
+	"precip":   {"mm", "in"},
+	"distance": {"km", "mi"},
+}
+
+// ParseUnits parses the units config key: a unit system, optionally followed by per-quantity
+// overrides, e.g. "imperial", "uk-hybrid" or "metric,wind=kn,pressure=inhg". Empty means metric.
+func ParseUnits(spec string) (Units, error) {
+	units := unitSystems["metric"]
+	for i, part := range strings.Split(strings.ToLower(spec), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		quantity, unit, isOverride := strings.Cut(part, "=")
+		if !isOverride {
+			system, ok := unitSystems[part]
+			if !ok {
+				return units, fmt.Errorf("unknown unit system %q, expected metric, imperial or uk-hybrid", part)
+			}
+			if i > 0 {
+				return units, fmt.Errorf("the unit system %q must come before the overrides", part)
+			}
+			units = system
+			continue
+		}
+
+		choices, ok := unitChoices[quantity]
+		if !ok {
+			return units, fmt.Errorf("unknown unit quantity %q, expected temp, wind, pressure, precip or distance", quantity)
+		}
+		if !slices.Contains(choices, unit) {
+			return units, fmt.Errorf("unknown %s unit %q, expected one of %s", quantity, unit, strings.Join(choices, ", "))
+		}
+		switch quantity {
+		case "temp":
+			units.temp = unit
+		case "wind":
+			units.wind = unit
+		case "pressure":
+			units.pressure = unit
+		case "precip":
+			units.precip = unit
+		case "distance":
+			units.distance = unit
+		}
+	}
+	return units, nil
+}
+
+// TempUnit returns the suffix of the temperatures, e.g. "°C".
+func (u Units) TempUnit() string {
+	if u.temp == "f" {
+		return "°F"
+	}
+	return "°C"
+}
+
+// WindUnit returns the suffix of the wind speeds, e.g. "km/h".
+func (u Units) WindUnit() string {
+	switch u.wind {
+	case "mph":
+		return "mph"
+	case "ms":
+		return "m/s"
+	case "kn":
+		return "kn"
+	}
+	return "km/h"
+}
+
+// PressureUnit returns the suffix of the pressures, e.g. "mb".
+func (u Units) PressureUnit() string {
+	switch u.pressure {
+	case "hpa":
+		return "hPa"
+	case "inhg":
+		return "inHg"
+	}
+	return "mb"
+}
+
+// PrecipUnit returns the suffix of the precipitation amounts, e.g. "mm".
+func (u Units) PrecipUnit() string {
+	if u.precip == "in" {
+		return "in"
+	}
+	return "mm"
+}
+
+// SnowUnit returns the suffix of the snowfall amounts, which follow the precipitation unit: "cm" or "in".
+func (u Units) SnowUnit() string {
+	if u.precip == "in" {
+		return "in"
+	}
+	return "cm"
+}
+
+// HeightUnit returns the suffix of the wave heights, which follow the precipitation unit: "m" or "ft".
+func (u Units) HeightUnit() string {
+	if u.precip == "in" {
+		return "ft"
+	}
+	return "m"
+}
+
+// VisUnit returns the suffix of the visibility distances, e.g. "km".
+func (u Units) VisUnit() string {
+	if u.distance == "mi" {
+		return "mi"
+	}
+	return "km"
+}
+
+// convertTemp converts a temperature in Celsius to the temperature unit.
+func (u Units) convertTemp(temp float64) float64 {
+	if u.temp == "f" {
+		return celsiusToFahrenheit(temp)
+	}
+	return temp
+}
+
+// convertWind converts a speed in km/h to the wind unit.
+func (u Units) convertWind(speed float64) float64 {
+	switch u.wind {
+	case "mph":
+		return kphToMph(speed)
+	case "ms":
+		return math.Round(speed/3.6*10) / 10
+	case "kn":
+		return math.Round(speed/1.852*10) / 10
+	}
+	return speed
+}
+
+// convertPressure converts a pressure in millibars to the pressure unit.
+func (u Units) convertPressure(pressure float64) float64 {
+	if u.pressure == "inhg" {
+		return mbToInHg(pressure)
+	}
+	return pressure
+}
+
+// convertPrecip converts a precipitation amount in millimetres to the precipitation unit.
+func (u Units) convertPrecip(length float64) float64 {
+	if u.precip == "in" {
+		return mmToInches(length)
+	}
+	return length
+}
+
+// convertSnow converts a snowfall amount in centimetres to the snowfall unit.
+func (u Units) convertSnow(length float64) float64 {
+	if u.precip == "in" {
+		return mmToInches(length * 10)
+	}
+	return length
+}
+
+// convertHeight converts a wave height in metres to the height unit.
+func (u Units) convertHeight(height float64) float64 {
+	if u.precip == "in" {
+		return metresToFeet(height)
+	}
+	return height
+}
+
+// convertDistance converts a distance in kilometres to the distance unit.
+func (u Units) convertDistance(distance float64) float64 {
+	if u.distance == "mi" {
+		return kmToMiles(distance)
+	}
+	return distance
+}
+
+// Temp returns the current temperature in the configured unit.
+func (v currentView) Temp() float64 { return v.convertTemp(v.TempC) }
+
+// FeelsLike returns the current "feels like" temperature in the configured unit.
+func (v currentView) FeelsLike() float64 { return v.convertTemp(v.FeelslikeC) }
+
+// Windchill returns the current wind chill in the configured unit.
+func (v currentView) Windchill() float64 { return v.convertTemp(v.WindchillC) }
+
+// Heatindex returns the current heat index in the configured unit.
+func (v currentView) Heatindex() float64 { return v.convertTemp(v.HeatindexC) }
+
+// Dewpoint returns the current dew point in the configured unit.
+func (v currentView) Dewpoint() float64 { return v.convertTemp(v.DewpointC) }
+
+// Wind returns the current wind speed in the configured unit.
+func (v currentView) Wind() float64 { return v.convertWind(v.WindKph) }
+
+// Gust returns the current wind gust speed in the configured unit.
+func (v currentView) Gust() float64 { return v.convertWind(v.GustKph) }
+
+// Pressure returns the current pressure in the configured unit.
+func (v currentView) Pressure() float64 { return v.convertPressure(v.PressureMb) }
+
+// Precip returns the current precipitation in the configured unit.
+func (v currentView) Precip() float64 { return v.convertPrecip(v.PrecipMm) }
+
+// Vis returns the current visibility in the configured unit.
+func (v currentView) Vis() float64 { return v.convertDistance(v.VisKm) }
+
+// hourView is the data of the forecast template: an hour of the forecast with the display units.
+type hourView struct {
+	HourlyForecast
+	Units
+	zone *time.Location
+}
+
+// LocationTime returns the time of the hour in the time zone of the location.
+func (v hourView) LocationTime() time.Time { return time.Unix(v.TimeEpoch, 0).In(v.zone) }
+
+// LocalTime returns the time of the hour in the time zone of the machine.
+func (v hourView) LocalTime() time.Time { return time.Unix(v.TimeEpoch, 0).Local() }
+
+// Temp returns the temperature of the hour in the configured unit.
+func (v hourView) Temp() float64 { return v.convertTemp(v.TempC) }
+
+// FeelsLike returns the "feels like" temperature of the hour in the configured unit.
+func (v hourView) FeelsLike() float64 { return v.convertTemp(v.FeelslikeC) }
+
+// Windchill returns the wind chill of the hour in the configured unit.
+func (v hourView) Windchill() float64 { return v.convertTemp(v.WindchillC) }
+
+// Heatindex returns the heat index of the hour in the configured unit.
+func (v hourView) Heatindex() float64 { return v.convertTemp(v.HeatindexC) }
+
+// Dewpoint returns the dew point of the hour in the configured unit.
+func (v hourView) Dewpoint() float64 { return v.convertTemp(v.DewpointC) }
+
+// Wind returns the wind speed of the hour in the configured unit.
+func (v hourView) Wind() float64 { return v.convertWind(v.WindKph) }
+
+// Gust returns the wind gust speed of the hour in the configured unit.
+func (v hourView) Gust() float64 { return v.convertWind(v.GustKph) }
+
+// Pressure returns the pressure of the hour in the configured unit.
+func (v hourView) Pressure() float64 { return v.convertPressure(v.PressureMb) }
+
+// Precip returns the precipitation of the hour in the configured unit.
+func (v hourView) Precip() float64 { return v.convertPrecip(v.PrecipMm) }
+
+// Snow returns the snowfall of the hour in the configured unit.
+func (v hourView) Snow() float64 { return v.convertSnow(v.SnowCm) }
+
+// Vis returns the visibility of the hour in the configured unit.
+func (v hourView) Vis() float64 { return v.convertDistance(v.VisKm) }
+
+// dayView is the data of the daily template: a day of the forecast with the display units.
+type dayView struct {
+	DailyForecast
+	Units
+}
+
+// newDayView returns the data of the daily template, with the astronomy times written in the locale.
+func newDayView(day DailyForecast, units Units, locale LocaleFormat) dayView {
+	day.Astro = day.Astro.inLocale(locale)
+	return dayView{day, units}
+}
+
+// MaxTemp returns the maximum temperature of the day in the configured unit.
+func (v dayView) MaxTemp() float64 { return v.convertTemp(v.MaxtempC) }
+
+// MinTemp returns the minimum temperature of the day in the configured unit.
+func (v dayView) MinTemp() float64 { return v.convertTemp(v.MintempC) }
+
+// AvgTemp returns the average temperature of the day in the configured unit.
+func (v dayView) AvgTemp() float64 { return v.convertTemp(v.AvgtempC) }
+
+// MaxWind returns the maximum wind speed of the day in the configured unit.
+func (v dayView) MaxWind() float64 { return v.convertWind(v.MaxwindKph) }
+
+// TotalPrecip returns the total precipitation of the day in the configured unit.
+func (v dayView) TotalPrecip() float64 { return v.convertPrecip(v.TotalprecipMm) }
+
+// TotalSnow returns the total snowfall of the day in the configured unit.
+func (v dayView) TotalSnow() float64 { return v.convertSnow(v.TotalsnowCm) }
+
+// marineView is the data of the marine template: an hour of the marine forecast with the display units.
+type marineView struct {
+	MarineHour
+	Units
+	zone *time.Location
+}
+
+// LocationTime returns the time of the hour in the time zone of the location.
+func (v marineView) LocationTime() time.Time { return time.Unix(v.TimeEpoch, 0).In(v.zone) }
+
+// LocalTime returns the time of the hour in the time zone of the machine.
+func (v marineView) LocalTime() time.Time { return time.Unix(v.TimeEpoch, 0).Local() }
+
+// WaveHeight returns the significant wave height of the hour in the configured unit.
+func (v marineView) WaveHeight() float64 { return v.convertHeight(v.WaveHeightM) }
+
+// SwellHeight returns the swell height of the hour in the configured unit.
+func (v marineView) SwellHeight() float64 { return v.convertHeight(v.SwellHeightM) }
+
+// WaterTemp returns the water temperature of the hour in the configured unit.
+func (v marineView) WaterTemp() float64 { return v.convertTemp(v.WaterTempC) }
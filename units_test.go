@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnits(t *testing.T) {
+	units, err := ParseUnits("")
+	assert.NoError(t, err)
+	assert.Equal(t, unitSystems["metric"], units)
+
+	units, err = ParseUnits("imperial")
+	assert.NoError(t, err)
+	assert.Equal(t, "°F", units.TempUnit())
+	assert.Equal(t, "mph", units.WindUnit())
+	assert.Equal(t, "inHg", units.PressureUnit())
+	assert.Equal(t, "in", units.PrecipUnit())
+	assert.Equal(t, "mi", units.VisUnit())
+
+	units, err = ParseUnits("uk-hybrid")
+	assert.NoError(t, err)
+	assert.Equal(t, "°C", units.TempUnit())
+	assert.Equal(t, "mph", units.WindUnit())
+	assert.Equal(t, "mi", units.VisUnit())
+
+	// Overrides apply on top of the unit system, which defaults to metric
+	units, err = ParseUnits("Metric, wind=kn, pressure=hpa")
+	assert.NoError(t, err)
+	assert.Equal(t, "°C", units.TempUnit())
+	assert.Equal(t, "kn", units.WindUnit())
+	assert.Equal(t, "hPa", units.PressureUnit())
+
+	units, err = ParseUnits("temp=f")
+	assert.NoError(t, err)
+	assert.Equal(t, "°F", units.TempUnit())
+	assert.Equal(t, "km/h", units.WindUnit())
+
+	_, err = ParseUnits("kelvin")
+	assert.EqualError(t, err, `unknown unit system "kelvin", expected metric, imperial or uk-hybrid`)
+	_, err = ParseUnits("wind=mph,imperial")
+	assert.EqualError(t, err, `the unit system "imperial" must come before the overrides`)
+	_, err = ParseUnits("rain=mm")
+	assert.EqualError(t, err, `unknown unit quantity "rain", expected temp, wind, pressure, precip or distance`)
+	_, err = ParseUnits("wind=furlongs")
+	assert.EqualError(t, err, `unknown wind unit "furlongs", expected one of kph, mph, ms, kn`)
+}
+
+func TestUnitViews(t *testing.T) {
+	imperial := unitSystems["imperial"]
+
+	current := currentView{WeatherCurrent: WeatherCurrent{TempC: 20, WindKph: 36, PressureMb: 1013, PrecipMm: 25.4, VisKm: 10}, Units: imperial}
+	assert.Equal(t, 68.0, current.Temp())
+	assert.Equal(t, 22.4, current.Wind())
+	assert.Equal(t, 29.91, current.Pressure())
+	assert.Equal(t, 1.0, current.Precip())
+	assert.Equal(t, 6.2, current.Vis())
+
+	metric := unitSystems["metric"]
+	hour := hourView{HourlyForecast{TempC: 20, FeelslikeC: 18, WindKph: 36, SnowCm: 2.54}, metric, time.UTC}
+	assert.Equal(t, 20.0, hour.Temp())
+	assert.Equal(t, 18.0, hour.FeelsLike())
+	assert.Equal(t, 36.0, hour.Wind())
+	assert.Equal(t, 2.54, hour.Snow())
+
+	hour.Units, _ = ParseUnits("wind=ms,precip=in")
+	assert.Equal(t, 10.0, hour.Wind())
+	assert.Equal(t, 1.0, hour.Snow())
+	assert.Equal(t, "in", hour.SnowUnit())
+
+	day := dayView{DailyForecast{MaxtempC: 30, MintempC: 10, MaxwindKph: 18.52}, imperial}
+	assert.Equal(t, 86.0, day.MaxTemp())
+	assert.Equal(t, 50.0, day.MinTemp())
+	day.Units, _ = ParseUnits("wind=kn")
+	assert.Equal(t, 10.0, day.MaxWind())
+}
@@ -1,43 +1,476 @@
 package main
 
 import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"time"
 )
 
 // WeatherProvider is an interface for fetching weather data.
+// Implementations translate their backend's response into the provider-neutral Weather model.
 type WeatherProvider interface {
-	GetWeather(config *Config) (*WeatherAPIResponse, error)
-	ToWeather(w *WeatherAPIResponse) *Weather
+	GetWeather(config *Config) (*Weather, error)
 	CleanCache(maxAge time.Duration)
 }
 
-// WeatherCurrent holds simplified current weather conditions.
-type WeatherCurrent struct {
-	Location string
-	Country  string
-	Emoji    string
-	TempC    float64
+// Weather holds the provider-neutral weather data used for formatting.
+type Weather struct {
+	Location       WeatherLocation  `json:"location"`
+	Current        WeatherCurrent   `json:"current"`
+	HourlyForecast []HourlyForecast `json:"hourly,omitempty"`
+	DailyForecast  []DailyForecast  `json:"daily,omitempty"`
+	Alerts         []WeatherAlert   `json:"alerts,omitempty"`
+	Marine         *WeatherMarine   `json:"marine,omitempty"`
+	Source         WeatherSource    `json:"source"`
 }
 
-// Weather holds the simplified weather data for formatting.
-type Weather struct {
-	Current        WeatherCurrent
-	HourlyForecast []HourlyForecast
+// TimeZone returns the time zone of the location, or the time zone of the machine
+// when the provider did not report a known one.
+func (w *Weather) TimeZone() *time.Location {
+	if w.Location.TzID != "" {
+		if zone, err := time.LoadLocation(w.Location.TzID); err == nil {
+			return zone
+		}
+	}
+	return time.Local
+}
+
+// ActiveAlerts returns the alerts that have not expired at the given time.
+func (w *Weather) ActiveAlerts(now time.Time) []WeatherAlert {
+	var alerts []WeatherAlert
+	for _, alert := range w.Alerts {
+		if alert.IsActive(now) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// WeatherLocation holds the metadata of the location the weather data refers to.
+type WeatherLocation struct {
+	Name           string  `json:"name"`
+	Region         string  `json:"region,omitempty"`
+	Country        string  `json:"country,omitempty"`
+	Lat            float64 `json:"lat"`
+	Lon            float64 `json:"lon"`
+	TzID           string  `json:"tz_id,omitempty"`
+	LocaltimeEpoch int64   `json:"localtime_epoch,omitempty"`
+}
+
+// LocationCandidate is a location matching a search query.
+type LocationCandidate struct {
+	ID      int     `json:"id,omitempty"`
+	Name    string  `json:"name"`
+	Region  string  `json:"region,omitempty"`
+	Country string  `json:"country,omitempty"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+// Query returns the location as an unambiguous query: its coordinates, "lat,lon", which every provider
+// understands, or its weatherapi.com id, "id:<id>", when by is "id".
+func (l LocationCandidate) Query(by string) (string, error) {
+	switch by {
+	case "", "coords":
+		return fmt.Sprintf("%.4f,%.4f", l.Lat, l.Lon), nil
+	case "id":
+		if l.ID == 0 {
+			return "", fmt.Errorf("the location %s has no id", l.Name)
+		}
+		return fmt.Sprintf("id:%d", l.ID), nil
+	}
+	return "", fmt.Errorf("unknown location query %q, expected coords or id", by)
 }
 
-// HourlyForecast holds the simplified hourly forecast data.
+// WeatherSource holds the attribution of the provider that produced the data.
+type WeatherSource struct {
+	Provider    string `json:"provider"`
+	Attribution string `json:"attribution,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// WeatherCurrent holds the current weather conditions.
+type WeatherCurrent struct {
+	Location         string  `json:"location"`
+	Country          string  `json:"country"`
+	LastUpdatedEpoch int64   `json:"last_updated_epoch,omitempty"`
+	Emoji            string  `json:"emoji"`
+	Condition        string  `json:"condition,omitempty"`
+	IsDay            bool    `json:"is_day"`
+	TempC            float64 `json:"temp_c"`
+	FeelslikeC       float64 `json:"feelslike_c"`
+	Humidity         int     `json:"humidity"`
+	WindKph          float64 `json:"wind_kph"`
+	WindDegree       int     `json:"wind_degree"`
+	WindDir          string  `json:"wind_dir,omitempty"`
+	GustKph          float64 `json:"gust_kph"`
+	PressureMb       float64 `json:"pressure_mb"`
+	PrecipMm         float64 `json:"precip_mm"`
+	Cloud            int     `json:"cloud"`
+	WindchillC       float64 `json:"windchill_c"`
+	HeatindexC       float64 `json:"heatindex_c"`
+	DewpointC        float64 `json:"dewpoint_c"`
+	VisKm            float64 `json:"vis_km"`
+	Uv               float64 `json:"uv"`
+
+	AirQuality *WeatherAirQuality `json:"air_quality,omitempty"`
+}
+
+// TempF returns the current temperature in Fahrenheit.
+func (c WeatherCurrent) TempF() float64 { return celsiusToFahrenheit(c.TempC) }
+
+// FeelslikeF returns the current "feels like" temperature in Fahrenheit.
+func (c WeatherCurrent) FeelslikeF() float64 { return celsiusToFahrenheit(c.FeelslikeC) }
+
+// WindchillF returns the current wind chill in Fahrenheit.
+func (c WeatherCurrent) WindchillF() float64 { return celsiusToFahrenheit(c.WindchillC) }
+
+// HeatindexF returns the current heat index in Fahrenheit.
+func (c WeatherCurrent) HeatindexF() float64 { return celsiusToFahrenheit(c.HeatindexC) }
+
+// DewpointF returns the current dew point in Fahrenheit.
+func (c WeatherCurrent) DewpointF() float64 { return celsiusToFahrenheit(c.DewpointC) }
+
+// WindMph returns the current wind speed in miles per hour.
+func (c WeatherCurrent) WindMph() float64 { return kphToMph(c.WindKph) }
+
+// GustMph returns the current wind gust speed in miles per hour.
+func (c WeatherCurrent) GustMph() float64 { return kphToMph(c.GustKph) }
+
+// PressureIn returns the current pressure in inches of mercury.
+func (c WeatherCurrent) PressureIn() float64 { return mbToInHg(c.PressureMb) }
+
+// PrecipIn returns the current precipitation in inches.
+func (c WeatherCurrent) PrecipIn() float64 { return mmToInches(c.PrecipMm) }
+
+// VisMiles returns the current visibility in miles.
+func (c WeatherCurrent) VisMiles() float64 { return kmToMiles(c.VisKm) }
+
+// HourlyForecast holds the forecast for a single hour.
 type HourlyForecast struct {
-	TimeEpoch  int64
-	Emoji      string
-	TempC      float64
-	FeelslikeC float64
+	TimeEpoch    int64   `json:"time_epoch"`
+	Emoji        string  `json:"emoji"`
+	Condition    string  `json:"condition,omitempty"`
+	IsDay        bool    `json:"is_day"`
+	TempC        float64 `json:"temp_c"`
+	FeelslikeC   float64 `json:"feelslike_c"`
+	Humidity     int     `json:"humidity"`
+	WindKph      float64 `json:"wind_kph"`
+	WindDegree   int     `json:"wind_degree"`
+	WindDir      string  `json:"wind_dir,omitempty"`
+	GustKph      float64 `json:"gust_kph"`
+	PressureMb   float64 `json:"pressure_mb"`
+	PrecipMm     float64 `json:"precip_mm"`
+	SnowCm       float64 `json:"snow_cm"`
+	Cloud        int     `json:"cloud"`
+	WindchillC   float64 `json:"windchill_c"`
+	HeatindexC   float64 `json:"heatindex_c"`
+	DewpointC    float64 `json:"dewpoint_c"`
+	WillItRain   bool    `json:"will_it_rain"`
+	ChanceOfRain int     `json:"chance_of_rain"`
+	WillItSnow   bool    `json:"will_it_snow"`
+	ChanceOfSnow int     `json:"chance_of_snow"`
+	VisKm        float64 `json:"vis_km"`
+	Uv           float64 `json:"uv"`
+}
+
+// TempF returns the temperature of the hour in Fahrenheit.
+func (h HourlyForecast) TempF() float64 { return celsiusToFahrenheit(h.TempC) }
+
+// FeelslikeF returns the "feels like" temperature of the hour in Fahrenheit.
+func (h HourlyForecast) FeelslikeF() float64 { return celsiusToFahrenheit(h.FeelslikeC) }
+
+// WindchillF returns the wind chill of the hour in Fahrenheit.
+func (h HourlyForecast) WindchillF() float64 { return celsiusToFahrenheit(h.WindchillC) }
+
+// HeatindexF returns the heat index of the hour in Fahrenheit.
+func (h HourlyForecast) HeatindexF() float64 { return celsiusToFahrenheit(h.HeatindexC) }
+
+// DewpointF returns the dew point of the hour in Fahrenheit.
+func (h HourlyForecast) DewpointF() float64 { return celsiusToFahrenheit(h.DewpointC) }
+
+// WindMph returns the wind speed of the hour in miles per hour.
+func (h HourlyForecast) WindMph() float64 { return kphToMph(h.WindKph) }
+
+// GustMph returns the wind gust speed of the hour in miles per hour.
+func (h HourlyForecast) GustMph() float64 { return kphToMph(h.GustKph) }
+
+// PressureIn returns the pressure of the hour in inches of mercury.
+func (h HourlyForecast) PressureIn() float64 { return mbToInHg(h.PressureMb) }
+
+// PrecipIn returns the precipitation of the hour in inches.
+func (h HourlyForecast) PrecipIn() float64 { return mmToInches(h.PrecipMm) }
+
+// SnowIn returns the snowfall of the hour in inches.
+func (h HourlyForecast) SnowIn() float64 { return mmToInches(h.SnowCm * 10) }
+
+// VisMiles returns the visibility of the hour in miles.
+func (h HourlyForecast) VisMiles() float64 { return kmToMiles(h.VisKm) }
+
+// DailyForecast holds the forecast summary for a single day.
+type DailyForecast struct {
+	DateEpoch     int64        `json:"date_epoch"`
+	Date          string       `json:"date"`
+	Emoji         string       `json:"emoji"`
+	Condition     string       `json:"condition,omitempty"`
+	MaxtempC      float64      `json:"maxtemp_c"`
+	MintempC      float64      `json:"mintemp_c"`
+	AvgtempC      float64      `json:"avgtemp_c"`
+	MaxwindKph    float64      `json:"maxwind_kph"`
+	TotalprecipMm float64      `json:"totalprecip_mm"`
+	TotalsnowCm   float64      `json:"totalsnow_cm"`
+	Avghumidity   int          `json:"avghumidity"`
+	ChanceOfRain  int          `json:"chance_of_rain"`
+	ChanceOfSnow  int          `json:"chance_of_snow"`
+	Uv            float64      `json:"uv"`
+	Astro         WeatherAstro `json:"astro"`
+}
+
+// WeatherAstro holds the astronomical data of a day. The times of the events are Unix timestamps,
+// 0 when the event does not happen that day. Templates read them through Sunrise, Sunset, Moonrise
+// and Moonset, written in the clock and time zone set with inLocale.
+type WeatherAstro struct {
+	SunriseEpoch     int64  `json:"sunrise_epoch,omitempty"`
+	SunsetEpoch      int64  `json:"sunset_epoch,omitempty"`
+	MoonriseEpoch    int64  `json:"moonrise_epoch,omitempty"`
+	MoonsetEpoch     int64  `json:"moonset_epoch,omitempty"`
+	MoonPhase        string `json:"moon_phase,omitempty"`
+	MoonIllumination int    `json:"moon_illumination"`
+
+	locale LocaleFormat // how the times are written
+}
+
+// WeatherAirQuality holds the air quality measurements, in μg/m³, and indices.
+type WeatherAirQuality struct {
+	Co           float64 `json:"co"`
+	No2          float64 `json:"no2"`
+	O3           float64 `json:"o3"`
+	So2          float64 `json:"so2"`
+	Pm25         float64 `json:"pm2_5"`
+	Pm10         float64 `json:"pm10"`
+	UsEpaIndex   int     `json:"us_epa_index"`
+	GbDefraIndex int     `json:"gb_defra_index"`
+}
+
+// usEpaCategories are the names of the US EPA air quality index categories 1 to 6.
+var usEpaCategories = []string{"Good", "Moderate", "Unhealthy for Sensitive Groups", "Unhealthy", "Very Unhealthy", "Hazardous"}
+
+// UsEpaCategory returns the name of the US EPA index category, e.g. "Moderate".
+func (a WeatherAirQuality) UsEpaCategory() string {
+	if a.UsEpaIndex < 1 || a.UsEpaIndex > len(usEpaCategories) {
+		return ""
+	}
+	return usEpaCategories[a.UsEpaIndex-1]
+}
+
+// GbDefraBand returns the band of the UK DEFRA index: Low (1-3), Moderate (4-6), High (7-9) or Very High (10).
+func (a WeatherAirQuality) GbDefraBand() string {
+	switch {
+	case a.GbDefraIndex >= 10:
+		return "Very High"
+	case a.GbDefraIndex >= 7:
+		return "High"
+	case a.GbDefraIndex >= 4:
+		return "Moderate"
+	case a.GbDefraIndex >= 1:
+		return "Low"
+	}
+	return ""
+}
+
+// CSSClass returns the waybar CSS class for the US EPA index, e.g. "aqi-moderate".
+func (a WeatherAirQuality) CSSClass() string {
+	category := a.UsEpaCategory()
+	if category == "" {
+		return ""
+	}
+	return "aqi-" + strings.ReplaceAll(strings.ToLower(strings.TrimSuffix(category, " Groups")), " ", "-")
+}
+
+// WeatherAlert holds a severe weather alert issued for the location.
+type WeatherAlert struct {
+	Headline       string `json:"headline"`
+	Event          string `json:"event,omitempty"`
+	Severity       string `json:"severity,omitempty"`
+	Urgency        string `json:"urgency,omitempty"`
+	Areas          string `json:"areas,omitempty"`
+	EffectiveEpoch int64  `json:"effective_epoch,omitempty"`
+	ExpiresEpoch   int64  `json:"expires_epoch,omitempty"`
+	Description    string `json:"description,omitempty"`
+	Instruction    string `json:"instruction,omitempty"`
+}
+
+// IsActive reports whether the alert has not expired at the given time. Alerts without an expiry stay active.
+func (a WeatherAlert) IsActive(now time.Time) bool {
+	return a.ExpiresEpoch == 0 || now.Unix() < a.ExpiresEpoch
+}
+
+// WeatherMarine holds the sea conditions and the tides of a coastal location.
+type WeatherMarine struct {
+	Hourly []MarineHour  `json:"hourly,omitempty"`
+	Tides  []WeatherTide `json:"tides,omitempty"`
+}
+
+// UpcomingTides returns the tides between the given time and a day later.
+func (m *WeatherMarine) UpcomingTides(now time.Time) []WeatherTide {
+	var tides []WeatherTide
+	for _, tide := range m.Tides {
+		if tide.TimeEpoch >= now.Unix() && tide.TimeEpoch < now.Add(24*time.Hour).Unix() {
+			tides = append(tides, tide)
+		}
+	}
+	return tides
+}
+
+// MarineHour holds the sea conditions of a single hour.
+type MarineHour struct {
+	TimeEpoch    int64   `json:"time_epoch"`
+	WaveHeightM  float64 `json:"wave_height_m"`
+	SwellHeightM float64 `json:"swell_height_m"`
+	SwellDegree  int     `json:"swell_degree"`
+	SwellDir     string  `json:"swell_dir,omitempty"`
+	SwellPeriodS float64 `json:"swell_period_s"`
+	WaterTempC   float64 `json:"water_temp_c"`
+}
+
+// WeatherTide holds a high or a low tide.
+type WeatherTide struct {
+	TimeEpoch int64   `json:"time_epoch"`
+	High      bool    `json:"high"`
+	HeightM   float64 `json:"height_m"`
+}
+
+// moonPhaseToEmojiMap maps the names of the moon phases to their emoji.
+var moonPhaseToEmojiMap = map[string]string{
+	"new moon":        "🌑",
+	"waxing crescent": "🌒",
+	"first quarter":   "🌓",
+	"waxing gibbous":  "🌔",
+	"full moon":       "🌕",
+	"waning gibbous":  "🌖",
+	"last quarter":    "🌗",
+	"third quarter":   "🌗",
+	"waning crescent": "🌘",
+}
+
+// inLocale returns the astronomy data with its times written in the clock and time zone of the locale.
+func (a WeatherAstro) inLocale(locale LocaleFormat) WeatherAstro {
+	a.locale = locale
+	return a
+}
+
+// Sunrise returns the time of sunrise, e.g. "08:28", or an empty string if unknown.
+func (a WeatherAstro) Sunrise() string { return a.clock(a.SunriseEpoch) }
+
+// Sunset returns the time of sunset, or an empty string if unknown.
+func (a WeatherAstro) Sunset() string { return a.clock(a.SunsetEpoch) }
+
+// Moonrise returns the time of moonrise, or an empty string if the moon does not rise that day.
+func (a WeatherAstro) Moonrise() string { return a.clock(a.MoonriseEpoch) }
+
+// Moonset returns the time of moonset, or an empty string if the moon does not set that day.
+func (a WeatherAstro) Moonset() string { return a.clock(a.MoonsetEpoch) }
+
+// clock writes a Unix timestamp in the clock of the locale, or the default one.
+func (a WeatherAstro) clock(epoch int64) string {
+	if epoch == 0 {
+		return ""
+	}
+	locale := a.locale
+	if locale.clock == "" {
+		locale = defaultLocaleFormat
+	}
+	if locale.zone == nil {
+		locale.zone = time.Local
+	}
+	return time.Unix(epoch, 0).In(locale.zone).Format(locale.clock)
 }
 
-// NewWeather creates a new Weather struct from the provider and config.
+// MoonEmoji returns the emoji of the moon phase, or an empty string if the phase is unknown.
+func (a WeatherAstro) MoonEmoji() string {
+	return moonPhaseToEmojiMap[strings.ToLower(strings.TrimSpace(a.MoonPhase))]
+}
+
+// celsiusToFahrenheit converts a temperature in degrees Celsius to degrees Fahrenheit.
+func celsiusToFahrenheit(temp float64) float64 {
+	return math.Round((temp*9/5+32)*10) / 10
+}
+
+// kphToMph converts a speed in kilometres per hour to miles per hour.
+func kphToMph(speed float64) float64 {
+	return kmToMiles(speed)
+}
+
+// kmToMiles converts a distance in kilometres to miles.
+func kmToMiles(distance float64) float64 {
+	return math.Round(distance/1.609344*10) / 10
+}
+
+// mbToInHg converts a pressure in millibars to inches of mercury.
+func mbToInHg(pressure float64) float64 {
+	return math.Round(pressure/33.8639*100) / 100
+}
+
+// mmToInches converts a length in millimetres to inches.
+func mmToInches(length float64) float64 {
+	return math.Round(length/25.4*100) / 100
+}
+
+// metresToFeet converts a length in metres to feet.
+func metresToFeet(length float64) float64 {
+	return math.Round(length/0.3048*10) / 10
+}
+
+// fahrenheitToCelsius converts a temperature in degrees Fahrenheit to degrees Celsius.
+func fahrenheitToCelsius(temp float64) float64 {
+	return math.Round((temp-32)*5/9*10) / 10
+}
+
+// mphToKph converts a speed in miles per hour to kilometres per hour.
+func mphToKph(speed float64) float64 {
+	return math.Round(speed*1.609344*10) / 10
+}
+
+// inHgToMb converts a pressure in inches of mercury to millibars.
+func inHgToMb(pressure float64) float64 {
+	return math.Round(pressure*33.8639*10) / 10
+}
+
+// inchesToMm converts a length in inches to millimetres.
+func inchesToMm(length float64) float64 {
+	return math.Round(length*25.4*10) / 10
+}
+
+// NewWeather fetches the weather from the provider for the given config.
 func NewWeather(provider WeatherProvider, config *Config) (*Weather, error) {
-	weatherAPIResponse, err := provider.GetWeather(config)
-	if err != nil {
-		return nil, err
+	return provider.GetWeather(config)
+}
+
+// NewWeatherComparison fetches the weather of every location to compare concurrently, through the
+// provider and its cache. The saved location names are resolved first, so they share the cache entries
+// of their locations. It fails if the weather of any of the locations cannot be fetched.
+func NewWeatherComparison(provider WeatherProvider, config *Config) ([]*Weather, error) {
+	weathers := make([]*Weather, len(config.CompareLocations))
+	errs := make([]error, len(config.CompareLocations))
+
+	var wg sync.WaitGroup
+	for i, name := range config.CompareLocations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			locationConfig := *config
+			locationConfig.Location = config.ResolveLocation(name)
+			weathers[i], errs[i] = NewWeather(provider, &locationConfig)
+		}()
 	}
-	return provider.ToWeather(weatherAPIResponse), nil
-}
\ No newline at end of file
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.CompareLocations[i], err)
+		}
+	}
+	return weathers, nil
+}
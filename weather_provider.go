@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultProvider is the name of the provider used when none is configured.
+const defaultProvider = "weatherapi"
+
+// defaultUserAgent identifies wayther to APIs that require it, such as api.met.no.
+const defaultUserAgent = "wayther github.com/dkarametos/wayther"
+
+// httpClient is the HTTP client used by the weather providers for all API requests.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Capability is an optional feature that a weather provider may support.
+type Capability string
+
+const (
+	CapabilityHourly  Capability = "hourly"
+	CapabilityDaily   Capability = "daily"
+	CapabilityAlerts  Capability = "alerts"
+	CapabilityAQI     Capability = "aqi"
+	CapabilityHistory Capability = "history"
+	CapabilityMarine  Capability = "marine"
+	CapabilitySearch  Capability = "search"
+)
+
+// HistoryProvider is implemented by the weather providers that can look up the weather of a past day.
+// Providers implementing it declare CapabilityHistory. nowFunc tells whether the day is over.
+type HistoryProvider interface {
+	GetHistory(config *Config, date string, nowFunc func() time.Time) (*Weather, error)
+}
+
+// LocationSearcher is implemented by the weather providers that can look up the locations matching a name.
+// Providers implementing it declare CapabilitySearch.
+type LocationSearcher interface {
+	SearchLocations(config *Config, query string) ([]LocationCandidate, error)
+}
+
+// ProviderInfo describes a weather provider in the provider registry.
+type ProviderInfo struct {
+	Name         string
+	Description  string
+	New          func(config *Config, cache *Cache) (WeatherProvider, error)
+	Capabilities []Capability
+	// CapabilitiesFrom, when set, names the provider the data comes from with the given config.
+	// Only the declared capabilities that this provider supports as well are then available.
+	CapabilitiesFrom func(config *Config) string
+}
+
+// Supports reports whether the provider has the given capability.
+func (i ProviderInfo) Supports(capability Capability) bool {
+	return slices.Contains(i.Capabilities, capability)
+}
+
+// SupportsWith reports whether the provider has the given capability with the given config.
+func (i ProviderInfo) SupportsWith(c *Config, capability Capability) bool {
+	if !i.Supports(capability) {
+		return false
+	}
+	if i.CapabilitiesFrom == nil {
+		return true
+	}
+	source, err := LookupProvider(i.CapabilitiesFrom(c))
+	return err == nil && source.Name != i.Name && source.SupportsWith(c, capability)
+}
+
+// providerRegistry holds the registered weather providers by name.
+var providerRegistry = map[string]ProviderInfo{}
+
+// RegisterProvider adds a weather provider to the registry.
+// Providers register themselves from an init function in their own file, so new
+// backends can be added without touching main.go. Registering a name twice panics.
+func RegisterProvider(info ProviderInfo) {
+	if _, found := providerRegistry[info.Name]; found {
+		panic(fmt.Sprintf("weather provider %q registered twice", info.Name))
+	}
+	providerRegistry[info.Name] = info
+}
+
+// LookupProvider returns the registered provider with the given name.
+// An empty name selects the default provider.
+func LookupProvider(name string) (ProviderInfo, error) {
+	if name == "" {
+		name = defaultProvider
+	}
+	info, found := providerRegistry[name]
+	if !found {
+		return ProviderInfo{}, fmt.Errorf("unknown weather provider %q", name)
+	}
+	return info, nil
+}
+
+// RegisteredProviders returns all the registered providers sorted by name.
+func RegisteredProviders() []ProviderInfo {
+	providers := make([]ProviderInfo, 0, len(providerRegistry))
+	for _, info := range providerRegistry {
+		providers = append(providers, info)
+	}
+	slices.SortFunc(providers, func(a, b ProviderInfo) int { return strings.Compare(a.Name, b.Name) })
+	return providers
+}
+
+// NewWeatherProvider constructs the registered WeatherProvider with the given name.
+func NewWeatherProvider(name string, config *Config, cache *Cache) (WeatherProvider, error) {
+	info, err := LookupProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	return info.New(config, cache)
+}
+
+// capabilityFlag ties a feature, named after its command-line flag, to the capability it requires from the providers.
+type capabilityFlag struct {
+	Flag       string
+	Capability Capability
+	Requested  func(cmd *cobra.Command, c *Config) bool // whether the command line or the config turns the feature on
+}
+
+// capabilityFlags lists the features that require a capability, in the order they are checked.
+var capabilityFlags = []capabilityFlag{
+	// The hourly forecast is on by default, so only asking for it explicitly requires the capability
+	{"forecast-hours", CapabilityHourly, func(cmd *cobra.Command, c *Config) bool {
+		return cmd.Flags().Changed("forecast-hours") && c.ForecastHours > 0
+	}},
+	{"forecast-days", CapabilityDaily, func(cmd *cobra.Command, c *Config) bool { return c.ForecastDays > 0 }},
+	{"alerts", CapabilityAlerts, func(cmd *cobra.Command, c *Config) bool { return c.Alerts }},
+	{"aqi", CapabilityAQI, func(cmd *cobra.Command, c *Config) bool { return c.AQI }},
+	{"marine", CapabilityMarine, func(cmd *cobra.Command, c *Config) bool { return c.Marine }},
+}
+
+// checkProviderCapabilities verifies that every provider in the configured chain exists and that
+// at least one of them supports each feature requested on the command line or in the config.
+// The providers lacking a feature leave it out when the chain falls back to them.
+func checkProviderCapabilities(cmd *cobra.Command, c *Config) error {
+	var providers []ProviderInfo
+	for _, name := range c.ProviderChain() {
+		info, err := LookupProvider(name)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, info)
+	}
+
+	for _, feature := range capabilityFlags {
+		if !feature.Requested(cmd, c) {
+			continue
+		}
+		supported := slices.ContainsFunc(providers, func(info ProviderInfo) bool {
+			return info.SupportsWith(c, feature.Capability)
+		})
+		if supported {
+			continue
+		}
+		if len(providers) == 1 {
+			return fmt.Errorf("weather provider %q does not support %s data (--%s)", providers[0].Name, feature.Capability, feature.Flag)
+		}
+		return fmt.Errorf("none of the weather providers %s supports %s data (--%s)", strings.Join(c.ProviderChain(), ", "), feature.Capability, feature.Flag)
+	}
+	return nil
+}
+
+// capableProviders returns the providers of the configured chain that support the capability, in order.
+func capableProviders(c *Config, capability Capability) []string {
+	var names []string
+	for _, name := range c.ProviderChain() {
+		if info, err := LookupProvider(name); err == nil && info.SupportsWith(c, capability) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// conditionMapping describes a provider specific weather condition.
+type conditionMapping struct {
+	Text string
+	Code int // the equivalent weatherapi.com condition code, used to pick the icon
+}
+
+// configuredProvider is a WeatherProvider that delegates to the provider chain selected in the configuration.
+// Providers that failed recently are remembered in the health store and skipped for a cool-down window.
+type configuredProvider struct {
+	cache  *Cache
+	health *ProviderHealth
+}
+
+// GetWeather fetches the weather from the providers named in the config, trying them in turn
+// until one answers. Providers that are cooling down after a failure are only tried as a last resort.
+func (p *configuredProvider) GetWeather(c *Config) (*Weather, error) {
+	chain := c.ProviderChain()
+	cooldown := c.ProviderCooldownDuration()
+
+	var healthy, coolingDown []string
+	for _, name := range chain {
+		if p.health != nil && p.health.IsCoolingDown(name, cooldown) {
+			coolingDown = append(coolingDown, name)
+		} else {
+			healthy = append(healthy, name)
+		}
+	}
+
+	var failures []string
+	for _, name := range append(healthy, coolingDown...) {
+		provider, err := NewWeatherProvider(name, c, p.cache)
+		if err != nil {
+			return nil, err
+		}
+
+		weather, err := provider.GetWeather(c)
+		if err != nil {
+			p.recordFailure(name, err)
+			if len(chain) == 1 {
+				return nil, err
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		if p.health != nil {
+			if err := p.health.RecordSuccess(name); err != nil {
+				log.Printf("Failed to save provider health: %v", err)
+			}
+		}
+		return weather, nil
+	}
+	return nil, fmt.Errorf("all weather providers failed: %s", strings.Join(failures, "; "))
+}
+
+// GetHistory looks up the weather of a past day, given as YYYY-MM-DD, from the first provider
+// of the chain that supports history lookups and answers.
+func (p *configuredProvider) GetHistory(c *Config, date string, nowFunc func() time.Time) (*Weather, error) {
+	var failures []string
+	var lastErr error
+	for _, name := range c.ProviderChain() {
+		info, err := LookupProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Supports(CapabilityHistory) {
+			continue
+		}
+
+		provider, err := info.New(c, p.cache)
+		if err != nil {
+			return nil, err
+		}
+		historyProvider, ok := provider.(HistoryProvider)
+		if !ok {
+			continue
+		}
+
+		weather, err := historyProvider.GetHistory(c, date, nowFunc)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			lastErr = err
+			continue
+		}
+		return weather, nil
+	}
+
+	switch len(failures) {
+	case 0:
+		return nil, fmt.Errorf("none of the weather providers %s supports %s data", strings.Join(c.ProviderChain(), ", "), CapabilityHistory)
+	case 1:
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("all weather providers failed: %s", strings.Join(failures, "; "))
+}
+
+// SearchLocations looks up the locations matching the query with the first provider of the chain
+// that supports location searches and answers.
+func (p *configuredProvider) SearchLocations(c *Config, query string) ([]LocationCandidate, error) {
+	var failures []string
+	var lastErr error
+	for _, name := range c.ProviderChain() {
+		info, err := LookupProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Supports(CapabilitySearch) {
+			continue
+		}
+
+		provider, err := info.New(c, p.cache)
+		if err != nil {
+			return nil, err
+		}
+		searcher, ok := provider.(LocationSearcher)
+		if !ok {
+			continue
+		}
+
+		candidates, err := searcher.SearchLocations(c, query)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			lastErr = err
+			continue
+		}
+		return candidates, nil
+	}
+
+	switch len(failures) {
+	case 0:
+		return nil, fmt.Errorf("none of the weather providers %s supports location searches", strings.Join(c.ProviderChain(), ", "))
+	case 1:
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("all weather providers failed: %s", strings.Join(failures, "; "))
+}
+
+// recordFailure remembers a provider failure in the health store, if there is one.
+func (p *configuredProvider) recordFailure(name string, err error) {
+	if p.health == nil {
+		return
+	}
+	if err := p.health.RecordFailure(name, err); err != nil {
+		log.Printf("Failed to save provider health: %v", err)
+	}
+}
+
+// CleanCache removes stale entries from the cache.
+func (p *configuredProvider) CleanCache(maxAge time.Duration) {
+	p.cache.Clean(maxAge)
+}
+
+// cacheKey returns the key under which a provider stores the weather of a location.
+func cacheKey(provider string, location string) string {
+	return provider + "|" + location
+}
+
+// fetchJSON performs a GET request to the given URL and decodes the JSON response into v.
+func fetchJSON(requestURL string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	return doJSONRequest(req, v)
+}
+
+// newIdentifiedRequest creates a GET request carrying the User-Agent from the config,
+// for APIs that require clients to identify themselves.
+func newIdentifiedRequest(c *Config, requestURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	userAgent := c.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+// doJSONRequest sends the request and decodes the JSON response into v.
+func doJSONRequest(req *http.Request, v interface{}) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make HTTP request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+	return nil
+}
+
+// windDirection converts a wind direction in degrees to a 16-point compass direction (e.g. "NNE").
+func windDirection(degree int) string {
+	directions := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+	index := int((float64(((degree%360)+360)%360) + 11.25) / 22.5)
+	return directions[index%16]
+}
+
+// msToKph converts a speed in metres per second to kilometres per hour.
+func msToKph(speed float64) float64 {
+	return math.Round(speed*3.6*10) / 10
+}
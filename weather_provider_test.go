@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWeatherProvider(t *testing.T) {
+	provider, err := NewWeatherProvider("", &Config{}, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &weatherapiProvider{}, provider)
+
+	provider, err = NewWeatherProvider("openmeteo", &Config{}, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &openMeteoProvider{}, provider)
+
+	_, err = NewWeatherProvider("unknown", &Config{}, nil)
+	assert.EqualError(t, err, "unknown weather provider \"unknown\"")
+}
+
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider(ProviderInfo{
+		Name: "mock",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &MockWeatherProvider{mockResponse: &WeatherAPIResponse{}}, nil
+		},
+		Capabilities: []Capability{CapabilityDaily},
+	})
+	defer delete(providerRegistry, "mock")
+
+	provider, err := NewWeatherProvider("mock", &Config{}, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &MockWeatherProvider{}, provider)
+
+	names := []string{}
+	for _, info := range RegisteredProviders() {
+		names = append(names, info.Name)
+	}
+	assert.Contains(t, names, "mock")
+	assert.IsIncreasing(t, names)
+
+	assert.Panics(t, func() { RegisterProvider(ProviderInfo{Name: "mock"}) })
+}
+
+func TestCheckProviderCapabilities(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().Int("forecast-hours", 23, "")
+
+	// Defaults never conflict with a provider
+	assert.NoError(t, checkProviderCapabilities(cmd, &Config{Provider: "weatherapi"}))
+
+	// Unknown providers are reported
+	assert.EqualError(t, checkProviderCapabilities(cmd, &Config{Providers: []string{"weatherapi", "nope"}}), "unknown weather provider \"nope\"")
+
+	// Explicitly requested features must be supported by a provider in the chain
+	cmd.Flags().Set("forecast-hours", "5")
+	assert.NoError(t, checkProviderCapabilities(cmd, &Config{Provider: "nws", ForecastHours: 5}))
+
+	RegisterProvider(ProviderInfo{Name: "mock"})
+	defer delete(providerRegistry, "mock")
+	assert.EqualError(t, checkProviderCapabilities(cmd, &Config{Provider: "mock", ForecastHours: 5}),
+		"weather provider \"mock\" does not support hourly data (--forecast-hours)")
+
+	// The chain may fall back to providers lacking the feature
+	assert.NoError(t, checkProviderCapabilities(cmd, &Config{Providers: []string{"weatherapi", "mock"}, ForecastHours: 5}))
+	assert.NoError(t, checkProviderCapabilities(cmd, &Config{Providers: []string{"mock", "weatherapi"}, ForecastHours: 5}))
+
+	// Features turned on in the config are checked too, in a fixed order
+	RegisterProvider(ProviderInfo{Name: "mock2"})
+	defer delete(providerRegistry, "mock2")
+	assert.EqualError(t, checkProviderCapabilities(cmd, &Config{Providers: []string{"mock", "mock2"}, ForecastHours: 5, Alerts: true, AQI: true, Marine: true}),
+		"none of the weather providers mock, mock2 supports hourly data (--forecast-hours)")
+
+	// Turning a feature off is always allowed
+	cmd.Flags().Set("forecast-hours", "0")
+	assert.NoError(t, checkProviderCapabilities(cmd, &Config{Provider: "mock"}))
+	for i := 0; i < 10; i++ {
+		assert.EqualError(t, checkProviderCapabilities(cmd, &Config{Provider: "mock", Alerts: true, AQI: true, Marine: true}),
+			"weather provider \"mock\" does not support alerts data (--alerts)")
+	}
+}
+
+func TestWindDirection(t *testing.T) {
+	assert.Equal(t, "N", windDirection(0))
+	assert.Equal(t, "N", windDirection(355))
+	assert.Equal(t, "NNE", windDirection(20))
+	assert.Equal(t, "E", windDirection(90))
+	assert.Equal(t, "SW", windDirection(225))
+	assert.Equal(t, "NW", windDirection(-45))
+}
+
+func TestConfiguredProvider_Fallback(t *testing.T) {
+	weatherapiRequests := 0
+
+	// weatherapi.com is down, Open-Meteo answers
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/forecast.json":
+			weatherapiRequests++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/v1/forecast":
+			w.Write([]byte(`{"timezone": "Europe/Brussels", "current": {"temperature_2m": 4.2, "weather_code": 0}}`))
+		default:
+			t.Errorf("Unexpected request to: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	originalURL, originalOpenMeteoURL := weatherAPIURL, openMeteoURL
+	weatherAPIURL = server.URL + "/v1/forecast.json"
+	openMeteoURL = server.URL + "/v1/forecast"
+	defer func() { weatherAPIURL, openMeteoURL = originalURL, originalOpenMeteoURL }()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cache, err := NewCache(configPath)
+	assert.NoError(t, err)
+	health, err := NewProviderHealth(configPath)
+	assert.NoError(t, err)
+
+	provider := &configuredProvider{cache: cache, health: health}
+	config := &Config{Location: "50.85,4.35", Providers: []string{"weatherapi", "openmeteo"}, NoCache: true}
+
+	weather, err := provider.GetWeather(config)
+	assert.NoError(t, err)
+	assert.Equal(t, "openmeteo", weather.Source.Provider)
+	assert.Equal(t, 4.2, weather.Current.TempC)
+	assert.True(t, health.IsCoolingDown("weatherapi", time.Minute))
+
+	// The failed provider is skipped during the cool-down window
+	_, err = provider.GetWeather(config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, weatherapiRequests)
+
+	// A single provider is still tried while cooling down and its error is returned as-is
+	config.Providers = []string{"weatherapi"}
+	_, err = provider.GetWeather(config)
+	assert.EqualError(t, err, "API request failed with status code 503: 503 Service Unavailable")
+
+	// When every provider fails, all the errors are reported, cooling down providers last
+	config.Providers = []string{"weatherapi", "openweathermap"}
+	_, err = provider.GetWeather(config)
+	assert.EqualError(t, err, "all weather providers failed: openweathermap: openweathermap_api_key is not set in the config; weatherapi: API request failed with status code 503: 503 Service Unavailable")
+}
+
+func TestConfiguredProvider_SearchLocations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 2629833, "name": "Springfield", "region": "Illinois", "country": "United States of America",
+			"lat": 39.8, "lon": -89.64}]`))
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPISearchURL
+	weatherAPISearchURL = server.URL
+	defer func() { weatherAPISearchURL = originalURL }()
+
+	// Providers without location searches are skipped
+	provider := &configuredProvider{cache: newMemoryCache()}
+	candidates, err := provider.SearchLocations(&Config{Providers: []string{"openmeteo", "weatherapi"}}, "Springfield")
+	assert.NoError(t, err)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, "Illinois", candidates[0].Region)
+	}
+
+	_, err = provider.SearchLocations(&Config{Providers: []string{"openmeteo", "nws"}}, "Springfield")
+	assert.EqualError(t, err, "none of the weather providers openmeteo, nws supports location searches")
+}
+
+func TestMoonEmoji(t *testing.T) {
+	assert.Equal(t, "🌕", WeatherAstro{MoonPhase: "Full Moon"}.MoonEmoji())
+	assert.Equal(t, "🌘", WeatherAstro{MoonPhase: "Waning Crescent"}.MoonEmoji())
+	assert.Equal(t, "🌗", WeatherAstro{MoonPhase: owmMoonPhaseName(0.75)}.MoonEmoji())
+	assert.Equal(t, "", WeatherAstro{}.MoonEmoji())
+}
+
+func TestWeatherAirQuality(t *testing.T) {
+	airQuality := WeatherAirQuality{UsEpaIndex: 3, GbDefraIndex: 7}
+	assert.Equal(t, "Unhealthy for Sensitive Groups", airQuality.UsEpaCategory())
+	assert.Equal(t, "High", airQuality.GbDefraBand())
+	assert.Equal(t, "aqi-unhealthy-for-sensitive", airQuality.CSSClass())
+
+	assert.Equal(t, "aqi-good", WeatherAirQuality{UsEpaIndex: 1}.CSSClass())
+	assert.Equal(t, "aqi-very-unhealthy", WeatherAirQuality{UsEpaIndex: 5}.CSSClass())
+	assert.Equal(t, "", WeatherAirQuality{}.CSSClass())
+	assert.Equal(t, "", WeatherAirQuality{}.GbDefraBand())
+}
+
+func TestWeatherTimeZone(t *testing.T) {
+	weather := &Weather{Location: WeatherLocation{TzID: "Asia/Tokyo"}}
+	assert.Equal(t, "Asia/Tokyo", weather.TimeZone().String())
+
+	weather.Location.TzID = "Europe/Atlantis"
+	assert.Equal(t, time.Local, weather.TimeZone(), "Unknown time zones should fall back to the machine's")
+
+	weather.Location.TzID = ""
+	assert.Equal(t, time.Local, weather.TimeZone())
+}
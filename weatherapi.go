@@ -3,7 +3,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"log"
+	"net/url"
 	"time"
 )
 
@@ -67,14 +68,42 @@ func getEmojiForWeatherCode(code int) string {
 	return "❓" // Default emoji for unknown codes
 }
 
+// weatherAPIMaxDays is the maximum number of forecast days of the WeatherAPI forecast endpoint.
+const weatherAPIMaxDays = 14
+
 // weatherAPIURL is the base URL for the WeatherAPI forecast endpoint.
 var weatherAPIURL = "https://api.weatherapi.com/v1/forecast.json"
 
+// weatherAPIHistoryURL is the base URL for the WeatherAPI history endpoint.
+var weatherAPIHistoryURL = "https://api.weatherapi.com/v1/history.json"
+
+// weatherAPISearchURL is the base URL for the WeatherAPI search endpoint.
+var weatherAPISearchURL = "https://api.weatherapi.com/v1/search.json"
+
+// weatherAPIMarineMaxDays is the maximum number of forecast days of the WeatherAPI marine endpoint.
+const weatherAPIMarineMaxDays = 7
+
+// weatherAPIMarineURL is the base URL for the WeatherAPI marine endpoint.
+var weatherAPIMarineURL = "https://api.weatherapi.com/v1/marine.json"
+
 // WeatherAPIResponse represents the top-level structure of the WeatherAPI forecast.json response.
+// The history.json and marine.json responses share the same structure.
 type WeatherAPIResponse struct {
 	Location Location `json:"location"`
 	Current  Current  `json:"current"`
 	Forecast Forecast `json:"forecast"`
+	Alerts   Alerts   `json:"alerts"`
+}
+
+// SearchResult represents a location of the WeatherAPI search.json response.
+type SearchResult struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Region  string  `json:"region"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	URL     string  `json:"url"`
 }
 
 // Location represents the location data.
@@ -91,43 +120,77 @@ type Location struct {
 
 // Current represents the current weather conditions.
 type Current struct {
-	LastUpdatedEpoch int64     `json:"last_updated_epoch"`
-	LastUpdated      string    `json:"last_updated"`
-	TempC            float64   `json:"temp_c"`
-	TempF            float64   `json:"temp_f"`
-	IsDay            int       `json:"is_day"`
-	Condition        Condition `json:"condition"`
-	WindMph          float64   `json:"wind_mph"`
-	WindKph          float64   `json:"wind_kph"`
-	WindDegree       int       `json:"wind_degree"`
-	WindDir          string    `json:"wind_dir"`
-	PressureMb       float64   `json:"pressure_mb"`
-	PressureIn       float64   `json:"pressure_in"`
-	PrecipMm         float64   `json:"precip_mm"`
-	PrecipIn         float64   `json:"precip_in"`
-	Humidity         int       `json:"humidity"`
-	Cloud            int       `json:"cloud"`
-	FeelslikeC       float64   `json:"feelslike_c"`
-	FeelslikeF       float64   `json:"feelslike_f"`
-	WindchillC       float64   `json:"windchill_c"`
-	WindchillF       float64   `json:"windchill_f"`
-	HeatindexC       float64   `json:"heatindex_c"`
-	HeatindexF       float64   `json:"heatindex_f"`
-	DewpointC        float64   `json:"dewpoint_c"`
-	DewpointF        float64   `json:"dewpoint_f"`
-	VisKm            float64   `json:"vis_km"`
-	VisMiles         float64   `json:"vis_miles"`
-	Uv               float64   `json:"uv"`
-	GustMph          float64   `json:"gust_mph"`
-	GustKph          float64   `json:"gust_kph"`
+	LastUpdatedEpoch int64       `json:"last_updated_epoch"`
+	LastUpdated      string      `json:"last_updated"`
+	TempC            float64     `json:"temp_c"`
+	TempF            float64     `json:"temp_f"`
+	IsDay            int         `json:"is_day"`
+	Condition        Condition   `json:"condition"`
+	WindMph          float64     `json:"wind_mph"`
+	WindKph          float64     `json:"wind_kph"`
+	WindDegree       int         `json:"wind_degree"`
+	WindDir          string      `json:"wind_dir"`
+	PressureMb       float64     `json:"pressure_mb"`
+	PressureIn       float64     `json:"pressure_in"`
+	PrecipMm         float64     `json:"precip_mm"`
+	PrecipIn         float64     `json:"precip_in"`
+	Humidity         int         `json:"humidity"`
+	Cloud            int         `json:"cloud"`
+	FeelslikeC       float64     `json:"feelslike_c"`
+	FeelslikeF       float64     `json:"feelslike_f"`
+	WindchillC       float64     `json:"windchill_c"`
+	WindchillF       float64     `json:"windchill_f"`
+	HeatindexC       float64     `json:"heatindex_c"`
+	HeatindexF       float64     `json:"heatindex_f"`
+	DewpointC        float64     `json:"dewpoint_c"`
+	DewpointF        float64     `json:"dewpoint_f"`
+	VisKm            float64     `json:"vis_km"`
+	VisMiles         float64     `json:"vis_miles"`
+	Uv               float64     `json:"uv"`
+	GustMph          float64     `json:"gust_mph"`
+	GustKph          float64     `json:"gust_kph"`
+	AirQuality       *AirQuality `json:"air_quality,omitempty"`
+}
+
+// AirQuality represents the air quality data, only present when requested with aqi=yes.
+type AirQuality struct {
+	Co           float64 `json:"co"`
+	No2          float64 `json:"no2"`
+	O3           float64 `json:"o3"`
+	So2          float64 `json:"so2"`
+	Pm25         float64 `json:"pm2_5"`
+	Pm10         float64 `json:"pm10"`
+	UsEpaIndex   int     `json:"us-epa-index"`
+	GbDefraIndex int     `json:"gb-defra-index"`
+}
+
+// Alerts represents the weather alerts, only present when requested with alerts=yes.
+type Alerts struct {
+	Alert []Alert `json:"alert"`
+}
+
+// Alert represents a single weather alert issued for the location.
+type Alert struct {
+	Headline    string `json:"headline"`
+	Msgtype     string `json:"msgtype"`
+	Severity    string `json:"severity"`
+	Urgency     string `json:"urgency"`
+	Areas       string `json:"areas"`
+	Category    string `json:"category"`
+	Certainty   string `json:"certainty"`
+	Event       string `json:"event"`
+	Note        string `json:"note"`
+	Effective   string `json:"effective"`
+	Expires     string `json:"expires"`
+	Desc        string `json:"desc"`
+	Instruction string `json:"instruction"`
 }
 
 // Condition represents the weather condition details.
 type Condition struct {
-	Text  string `json:"text"`
-	Icon  string `json:"icon"`
-	Code  int    `json:"code"`
-	Emoji string `json:"emoji,omitempty"`
+	Text string `json:"text"`
+	Icon string `json:"icon"`
+	Code int    `json:"code"`
 }
 
 // Forecast represents the forecast data.
@@ -166,6 +229,19 @@ type Day struct {
 	DailyChanceOfSnow int       `json:"daily_chance_of_snow"`
 	Condition         Condition `json:"condition"`
 	Uv                float64   `json:"uv"`
+	Tides             []Tides   `json:"tides,omitempty"`
+}
+
+// Tides holds the tides of a day, only present in the marine.json response.
+type Tides struct {
+	Tide []Tide `json:"tide"`
+}
+
+// Tide represents a high or low tide. The API reports the height as a string.
+type Tide struct {
+	TideTime     string      `json:"tide_time"`
+	TideHeightMt json.Number `json:"tide_height_mt"`
+	TideType     string      `json:"tide_type"`
 }
 
 // Astro represents astronomical data.
@@ -218,6 +294,25 @@ type Hour struct {
 	Uv           float64   `json:"uv"`
 	ShortRad     float64   `json:"short_rad"`
 	DiffRad      float64   `json:"diff_rad"`
+
+	// Sea conditions, only present in the marine.json response
+	SigHtMt         float64 `json:"sig_ht_mt"`
+	SwellHtMt       float64 `json:"swell_ht_mt"`
+	SwellDir        float64 `json:"swell_dir"`
+	SwellDir16Point string  `json:"swell_dir_16_point"`
+	SwellPeriodSecs float64 `json:"swell_period_secs"`
+	WaterTempC      float64 `json:"water_temp_c"`
+}
+
+func init() {
+	RegisterProvider(ProviderInfo{
+		Name:        "weatherapi",
+		Description: "weatherapi.com forecast API (requires apiKey)",
+		New: func(config *Config, cache *Cache) (WeatherProvider, error) {
+			return &weatherapiProvider{cache: cache}, nil
+		},
+		Capabilities: []Capability{CapabilityHourly, CapabilityDaily, CapabilityAlerts, CapabilityAQI, CapabilityHistory, CapabilityMarine, CapabilitySearch},
+	})
 }
 
 // weatherapiProvider is the real implementation of WeatherProvider that uses the weather API.
@@ -226,54 +321,124 @@ type weatherapiProvider struct {
 }
 
 // GetWeather fetches weather forecast data from the WeatherAPI for a given location.
-// It takes the location (e.g., "London") and an API key as input.
-// It returns a pointer to a WeatherAPIResponse struct containing the parsed data,
+// It takes the location (e.g., "London") and an API key from the config.
+// It returns a pointer to a provider-neutral Weather struct built from the parsed data,
 // or an error if the request fails or the response cannot be decoded.
-func (p *weatherapiProvider) GetWeather(c *Config) (*WeatherAPIResponse, error) {
+func (p *weatherapiProvider) GetWeather(c *Config) (*Weather, error) {
+	options := fmt.Sprintf("days=%d&aqi=%s&alerts=%s", c.ForecastDaysToFetch(weatherAPIMaxDays), yesNo(c.AQI), yesNo(c.Alerts))
+	if c.Lang != "" {
+		options += "&lang=" + url.QueryEscape(c.Lang)
+	}
+	key := cacheKey("weatherapi", c.Location) + "|" + options
+	if c.Marine {
+		key += "|marine"
+	}
+
 	// Check cache first
 	if !c.NoCache {
-		if entry, found := p.cache.Get(c.Location); found && !entry.IsStale(time.Hour) {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
 			return entry.Weather, nil
 		}
 	}
 
-	url := fmt.Sprintf("%s?key=%s&q=%s&days=2&aqi=no&alerts=no", weatherAPIURL, c.APIKey, c.Location)
+	location := url.QueryEscape(c.Location)
+	requestURL := fmt.Sprintf("%s?key=%s&q=%s&%s", weatherAPIURL, c.APIKey, location, options)
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make HTTP request")
+	var weatherResp WeatherAPIResponse
+	if err := fetchJSON(requestURL, &weatherResp); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, resp.Status)
+	weather := weatherResp.toWeather()
+
+	if c.Marine {
+		marineURL := fmt.Sprintf("%s?key=%s&q=%s&days=%d&tides=yes", weatherAPIMarineURL, c.APIKey, location,
+			c.ForecastDaysToFetch(weatherAPIMarineMaxDays))
+
+		var marineResp WeatherAPIResponse
+		if err := fetchJSON(marineURL, &marineResp); err != nil {
+			return nil, fmt.Errorf("failed to fetch the marine forecast: %w", err)
+		}
+		weather.Marine = marineResp.toWeatherMarine(weather.TimeZone())
 	}
 
-	var weatherResp WeatherAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&weatherResp); err != nil {
-		return nil, fmt.Errorf("failed to decode API response: %w", err)
+	// Save to cache
+	if err := p.cache.Set(key, weather); err != nil {
+		// Log the error, but don't block the user
+		log.Printf("Failed to save to cache: %v", err)
 	}
 
-	// Populate emojis
-	weatherResp.Current.Condition.Emoji = getEmojiForWeatherCode(weatherResp.Current.Condition.Code)
+	return weather, nil
+}
+
+// GetHistory fetches the weather of a past day, given as YYYY-MM-DD, from the WeatherAPI history endpoint.
+// The current conditions are filled in with the summary of the day. Days that are over everywhere
+// cannot change anymore, so they are cached permanently.
+func (p *weatherapiProvider) GetHistory(c *Config, date string, nowFunc func() time.Time) (*Weather, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+	}
 
-	for i := range weatherResp.Forecast.Forecastday {
-		// Day condition
-		weatherResp.Forecast.Forecastday[i].Day.Condition.Emoji = getEmojiForWeatherCode(weatherResp.Forecast.Forecastday[i].Day.Condition.Code)
+	options := "dt=" + date
+	if c.Lang != "" {
+		options += "&lang=" + url.QueryEscape(c.Lang)
+	}
+	key := cacheKey("weatherapi-history", c.Location) + "|" + options
 
-		// Hourly conditions
-		for j := range weatherResp.Forecast.Forecastday[i].Hour {
-			weatherResp.Forecast.Forecastday[i].Hour[j].Condition.Emoji = getEmojiForWeatherCode(weatherResp.Forecast.Forecastday[i].Hour[j].Condition.Code)
+	// Check cache first
+	if !c.NoCache {
+		if entry, found := p.cache.Get(key); found && !entry.IsStale(time.Hour) {
+			return entry.Weather, nil
 		}
 	}
 
+	requestURL := fmt.Sprintf("%s?key=%s&q=%s&%s", weatherAPIHistoryURL, c.APIKey, url.QueryEscape(c.Location), options)
+
+	var historyResp WeatherAPIResponse
+	if err := fetchJSON(requestURL, &historyResp); err != nil {
+		return nil, err
+	}
+	if len(historyResp.Forecast.Forecastday) == 0 {
+		return nil, fmt.Errorf("no weather history for %s on %s", c.Location, date)
+	}
+
+	weather := historyResp.toWeather()
+	weather.Current = historyResp.Forecast.Forecastday[0].toWeatherCurrent(historyResp.Location)
+
+	// The last time zone to end a day is 12 hours behind UTC
+	final := nowFunc().After(day.AddDate(0, 0, 1).Add(12 * time.Hour))
+
 	// Save to cache
-	if err := p.cache.Set(c.Location, &weatherResp); err != nil {
+	if err := p.cache.SetEntry(key, CacheEntry{Weather: weather, Permanent: final}); err != nil {
 		// Log the error, but don't block the user
-		fmt.Printf("Failed to save to cache: %v\n", err)
+		log.Printf("Failed to save to cache: %v", err)
+	}
+
+	return weather, nil
+}
+
+// SearchLocations looks up the locations matching the query with the WeatherAPI search endpoint.
+func (p *weatherapiProvider) SearchLocations(c *Config, query string) ([]LocationCandidate, error) {
+	searchURL := fmt.Sprintf("%s?key=%s&q=%s", weatherAPISearchURL, c.APIKey, url.QueryEscape(query))
+
+	var results []SearchResult
+	if err := fetchJSON(searchURL, &results); err != nil {
+		return nil, err
 	}
 
-	return &weatherResp, nil
+	candidates := make([]LocationCandidate, len(results))
+	for i, result := range results {
+		candidates[i] = LocationCandidate{
+			ID:      result.ID,
+			Name:    result.Name,
+			Region:  result.Region,
+			Country: result.Country,
+			Lat:     result.Lat,
+			Lon:     result.Lon,
+		}
+	}
+	return candidates, nil
 }
 
 // CleanCache removes stale entries from the cache.
@@ -281,29 +446,238 @@ func (p *weatherapiProvider) CleanCache(maxAge time.Duration) {
 	p.cache.Clean(maxAge)
 }
 
-// ToWeather maps the WeatherAPIResponse to the Weather struct.
-func (p *weatherapiProvider) ToWeather(w *WeatherAPIResponse) *Weather {
-	var hourlyForecasts []HourlyForecast
-	if len(w.Forecast.Forecastday) > 0 {
-		for _, forecastday := range w.Forecast.Forecastday {
-			for _, hour := range forecastday.Hour {
-				hourlyForecasts = append(hourlyForecasts, HourlyForecast{
-					TimeEpoch:  hour.TimeEpoch,
-					Emoji:      hour.Condition.Emoji,
-					TempC:      hour.TempC,
-					FeelslikeC: hour.FeelslikeC,
+// toWeatherAirQuality maps the air quality data to the provider-neutral WeatherAirQuality struct.
+func (a *AirQuality) toWeatherAirQuality() *WeatherAirQuality {
+	if a == nil {
+		return nil
+	}
+	return &WeatherAirQuality{
+		Co:           a.Co,
+		No2:          a.No2,
+		O3:           a.O3,
+		So2:          a.So2,
+		Pm25:         a.Pm25,
+		Pm10:         a.Pm10,
+		UsEpaIndex:   a.UsEpaIndex,
+		GbDefraIndex: a.GbDefraIndex,
+	}
+}
+
+// toWeatherAlerts maps the alerts to the provider-neutral WeatherAlert structs.
+// Alerts without a headline are named after their event.
+func (a Alerts) toWeatherAlerts() []WeatherAlert {
+	var alerts []WeatherAlert
+	for _, alert := range a.Alert {
+		headline := alert.Headline
+		if headline == "" {
+			headline = alert.Event
+		}
+		alerts = append(alerts, WeatherAlert{
+			Headline:       headline,
+			Event:          alert.Event,
+			Severity:       alert.Severity,
+			Urgency:        alert.Urgency,
+			Areas:          alert.Areas,
+			EffectiveEpoch: parseAlertTime(alert.Effective),
+			ExpiresEpoch:   parseAlertTime(alert.Expires),
+			Description:    alert.Desc,
+			Instruction:    alert.Instruction,
+		})
+	}
+	return alerts
+}
+
+// parseAlertTime parses the RFC 3339 time of an alert into a Unix timestamp, or 0 if it is missing or invalid.
+func parseAlertTime(value string) int64 {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// toWeatherAstro maps the astronomy data of a day to the provider-neutral WeatherAstro struct.
+// The API reports the times local to the location, e.g. "08:28 AM", and "No moonrise" for missing events.
+func (a Astro) toWeatherAstro(date string, zone *time.Location) WeatherAstro {
+	return WeatherAstro{
+		SunriseEpoch:     parseAstroTime(date, a.Sunrise, zone),
+		SunsetEpoch:      parseAstroTime(date, a.Sunset, zone),
+		MoonriseEpoch:    parseAstroTime(date, a.Moonrise, zone),
+		MoonsetEpoch:     parseAstroTime(date, a.Moonset, zone),
+		MoonPhase:        a.MoonPhase,
+		MoonIllumination: a.MoonIllumination,
+	}
+}
+
+// parseAstroTime parses the clock time of an astronomical event on the given day into a Unix timestamp,
+// or 0 if the event does not happen that day.
+func parseAstroTime(date string, clock string, zone *time.Location) int64 {
+	t, err := time.ParseInLocation("2006-01-02 03:04 PM", date+" "+clock, zone)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// toWeatherMarine maps the marine.json response to the provider-neutral WeatherMarine struct.
+// The tide times are local to the location, in the given time zone.
+func (w *WeatherAPIResponse) toWeatherMarine(zone *time.Location) *WeatherMarine {
+	marine := &WeatherMarine{}
+	for _, forecastday := range w.Forecast.Forecastday {
+		for _, tides := range forecastday.Day.Tides {
+			for _, tide := range tides.Tide {
+				tideTime, err := time.ParseInLocation("2006-01-02 15:04", tide.TideTime, zone)
+				if err != nil {
+					continue
+				}
+				height, _ := tide.TideHeightMt.Float64()
+				marine.Tides = append(marine.Tides, WeatherTide{
+					TimeEpoch: tideTime.Unix(),
+					High:      tide.TideType == "HIGH",
+					HeightM:   height,
 				})
 			}
 		}
+
+		for _, hour := range forecastday.Hour {
+			marine.Hourly = append(marine.Hourly, MarineHour{
+				TimeEpoch:    hour.TimeEpoch,
+				WaveHeightM:  hour.SigHtMt,
+				SwellHeightM: hour.SwellHtMt,
+				SwellDegree:  int(hour.SwellDir),
+				SwellDir:     hour.SwellDir16Point,
+				SwellPeriodS: hour.SwellPeriodSecs,
+				WaterTempC:   hour.WaterTempC,
+			})
+		}
+	}
+	return marine
+}
+
+// toWeatherCurrent summarizes the day as the current conditions, for the weather history.
+func (f Forecastday) toWeatherCurrent(location Location) WeatherCurrent {
+	return WeatherCurrent{
+		Location:         location.Name,
+		Country:          location.Country,
+		LastUpdatedEpoch: f.DateEpoch,
+		Emoji:            getEmojiForWeatherCode(f.Day.Condition.Code),
+		Condition:        f.Day.Condition.Text,
+		IsDay:            true,
+		TempC:            f.Day.AvgtempC,
+		FeelslikeC:       f.Day.AvgtempC,
+		Humidity:         f.Day.Avghumidity,
+		WindKph:          f.Day.MaxwindKph,
+		PrecipMm:         f.Day.TotalprecipMm,
+		VisKm:            f.Day.AvgvisKm,
+		Uv:               f.Day.Uv,
+	}
+}
+
+// yesNo formats a boolean as a "yes" or "no" query parameter.
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// toWeather maps the WeatherAPIResponse to the provider-neutral Weather struct.
+func (w *WeatherAPIResponse) toWeather() *Weather {
+	location := WeatherLocation{
+		Name:           w.Location.Name,
+		Region:         w.Location.Region,
+		Country:        w.Location.Country,
+		Lat:            w.Location.Lat,
+		Lon:            w.Location.Lon,
+		TzID:           w.Location.TzID,
+		LocaltimeEpoch: w.Location.LocaltimeEpoch,
+	}
+	zone := (&Weather{Location: location}).TimeZone()
+
+	var hourlyForecasts []HourlyForecast
+	var dailyForecasts []DailyForecast
+	for _, forecastday := range w.Forecast.Forecastday {
+		dailyForecasts = append(dailyForecasts, DailyForecast{
+			DateEpoch:     forecastday.DateEpoch,
+			Date:          forecastday.Date,
+			Emoji:         getEmojiForWeatherCode(forecastday.Day.Condition.Code),
+			Condition:     forecastday.Day.Condition.Text,
+			MaxtempC:      forecastday.Day.MaxtempC,
+			MintempC:      forecastday.Day.MintempC,
+			AvgtempC:      forecastday.Day.AvgtempC,
+			MaxwindKph:    forecastday.Day.MaxwindKph,
+			TotalprecipMm: forecastday.Day.TotalprecipMm,
+			TotalsnowCm:   forecastday.Day.TotalsnowCm,
+			Avghumidity:   forecastday.Day.Avghumidity,
+			ChanceOfRain:  forecastday.Day.DailyChanceOfRain,
+			ChanceOfSnow:  forecastday.Day.DailyChanceOfSnow,
+			Uv:            forecastday.Day.Uv,
+			Astro:         forecastday.Astro.toWeatherAstro(forecastday.Date, zone),
+		})
+
+		for _, hour := range forecastday.Hour {
+			hourlyForecasts = append(hourlyForecasts, HourlyForecast{
+				TimeEpoch:    hour.TimeEpoch,
+				Emoji:        getEmojiForWeatherCode(hour.Condition.Code),
+				Condition:    hour.Condition.Text,
+				IsDay:        hour.IsDay == 1,
+				TempC:        hour.TempC,
+				FeelslikeC:   hour.FeelslikeC,
+				Humidity:     hour.Humidity,
+				WindKph:      hour.WindKph,
+				WindDegree:   hour.WindDegree,
+				WindDir:      hour.WindDir,
+				GustKph:      hour.GustKph,
+				PressureMb:   hour.PressureMb,
+				PrecipMm:     hour.PrecipMm,
+				SnowCm:       hour.SnowCm,
+				Cloud:        hour.Cloud,
+				WindchillC:   hour.WindchillC,
+				HeatindexC:   hour.HeatindexC,
+				DewpointC:    hour.DewpointC,
+				WillItRain:   hour.WillItRain == 1,
+				ChanceOfRain: hour.ChanceOfRain,
+				WillItSnow:   hour.WillItSnow == 1,
+				ChanceOfSnow: hour.ChanceOfSnow,
+				VisKm:        hour.VisKm,
+				Uv:           hour.Uv,
+			})
+		}
 	}
 
 	return &Weather{
+		Location: location,
 		Current: WeatherCurrent{
-			Location: w.Location.Name,
-			Country:  w.Location.Country,
-			Emoji:    w.Current.Condition.Emoji,
-			TempC:    w.Current.TempC,
+			Location:         w.Location.Name,
+			Country:          w.Location.Country,
+			LastUpdatedEpoch: w.Current.LastUpdatedEpoch,
+			Emoji:            getEmojiForWeatherCode(w.Current.Condition.Code),
+			Condition:        w.Current.Condition.Text,
+			IsDay:            w.Current.IsDay == 1,
+			TempC:            w.Current.TempC,
+			FeelslikeC:       w.Current.FeelslikeC,
+			Humidity:         w.Current.Humidity,
+			WindKph:          w.Current.WindKph,
+			WindDegree:       w.Current.WindDegree,
+			WindDir:          w.Current.WindDir,
+			GustKph:          w.Current.GustKph,
+			PressureMb:       w.Current.PressureMb,
+			PrecipMm:         w.Current.PrecipMm,
+			Cloud:            w.Current.Cloud,
+			WindchillC:       w.Current.WindchillC,
+			HeatindexC:       w.Current.HeatindexC,
+			DewpointC:        w.Current.DewpointC,
+			VisKm:            w.Current.VisKm,
+			Uv:               w.Current.Uv,
+			AirQuality:       w.Current.AirQuality.toWeatherAirQuality(),
 		},
 		HourlyForecast: hourlyForecasts,
+		DailyForecast:  dailyForecasts,
+		Alerts:         w.Alerts.toWeatherAlerts(),
+		Source: WeatherSource{
+			Provider:    "weatherapi",
+			Attribution: "Powered by WeatherAPI.com",
+			URL:         "https://www.weatherapi.com/",
+		},
 	}
-}
\ No newline at end of file
+}
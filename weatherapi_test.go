@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,6 +27,12 @@ func TestWeatherProvider_GetWeather(t *testing.T) {
 		if r.URL.Query().Get("key") != "test_api_key" {
 			t.Errorf("Expected query parameter 'key' to be 'test_api_key', got: %s", r.URL.Query().Get("key"))
 		}
+		if r.URL.Query().Get("days") != "3" {
+			t.Errorf("Expected query parameter 'days' to be '3', got: %s", r.URL.Query().Get("days"))
+		}
+		if r.URL.Query().Get("lang") != "fr" {
+			t.Errorf("Expected query parameter 'lang' to be 'fr', got: %s", r.URL.Query().Get("lang"))
+		}
 
 		// Provide a sample JSON response
 		sampleResponse := WeatherAPIResponse{
@@ -61,8 +68,10 @@ func TestWeatherProvider_GetWeather(t *testing.T) {
 
 	provider := &weatherapiProvider{cache: cache}
 	config := &Config{
-		Location: "London",
-		APIKey:   "test_api_key",
+		Location:     "London",
+		APIKey:       "test_api_key",
+		ForecastDays: 3,
+		Lang:         "fr",
 	}
 	weather, err := provider.GetWeather(config)
 	if err != nil {
@@ -74,8 +83,227 @@ func TestWeatherProvider_GetWeather(t *testing.T) {
 	assert.Equal(t, "London", weather.Location.Name)
 	assert.Equal(t, 10.0, weather.Current.TempC)
 	assert.Equal(t, 70, weather.Current.Humidity)
-	assert.Equal(t, "Partly cloudy", weather.Current.Condition.Text)
-	assert.Equal(t, 1003, weather.Current.Condition.Code)
+	assert.Equal(t, "Partly cloudy", weather.Current.Condition)
+	assert.Equal(t, getEmojiForWeatherCode(1003), weather.Current.Emoji)
+	assert.Equal(t, "weatherapi", weather.Source.Provider)
+}
+
+func TestWeatherProvider_GetWeather_AirQuality(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("aqi") != "yes" {
+			t.Errorf("Expected query parameter 'aqi' to be 'yes', got: %s", r.URL.Query().Get("aqi"))
+		}
+		w.Write([]byte(`{"location": {"name": "London"}, "current": {"temp_c": 10,
+			"air_quality": {"co": 230.3, "no2": 13.5, "o3": 55.1, "so2": 3.2, "pm2_5": 8.9, "pm10": 10.2, "us-epa-index": 2, "gb-defra-index": 4}}}`))
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = originalURL }()
+
+	provider := &weatherapiProvider{cache: newMemoryCache()}
+	weather, err := provider.GetWeather(&Config{Location: "London", APIKey: "test_api_key", AQI: true})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	if assert.NotNil(t, weather.Current.AirQuality) {
+		assert.Equal(t, 8.9, weather.Current.AirQuality.Pm25)
+		assert.Equal(t, 10.2, weather.Current.AirQuality.Pm10)
+		assert.Equal(t, 2, weather.Current.AirQuality.UsEpaIndex)
+		assert.Equal(t, 4, weather.Current.AirQuality.GbDefraIndex)
+	}
+}
+
+func TestWeatherProvider_GetWeather_LocationWithSpace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "New York" {
+			t.Errorf("Expected query parameter 'q' to be 'New York', got: %s", r.URL.Query().Get("q"))
+		}
+		w.Write([]byte(`{"location": {"name": "New York"}, "current": {"temp_c": 12}}`))
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = originalURL }()
+
+	provider := &weatherapiProvider{cache: newMemoryCache()}
+	weather, err := provider.GetWeather(&Config{Location: "New York", APIKey: "test_api_key"})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+	assert.Equal(t, "New York", weather.Location.Name)
+}
+
+func TestWeatherProvider_GetWeather_Alerts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("alerts") != "yes" {
+			t.Errorf("Expected query parameter 'alerts' to be 'yes', got: %s", r.URL.Query().Get("alerts"))
+		}
+		w.Write([]byte(`{"location": {"name": "Miami"}, "current": {"temp_c": 28}, "alerts": {"alert": [
+			{"headline": "", "severity": "Extreme", "event": "Hurricane Warning", "effective": "2025-09-01T12:00:00-04:00",
+			 "expires": "2025-09-02T12:00:00-04:00", "desc": "Hurricane conditions are expected.", "instruction": "Evacuate."}]}}`))
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPIURL
+	weatherAPIURL = server.URL
+	defer func() { weatherAPIURL = originalURL }()
+
+	provider := &weatherapiProvider{cache: newMemoryCache()}
+	weather, err := provider.GetWeather(&Config{Location: "Miami", APIKey: "test_api_key", Alerts: true})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+
+	if assert.Len(t, weather.Alerts, 1) {
+		alert := weather.Alerts[0]
+		assert.Equal(t, "Hurricane Warning", alert.Headline, "Alerts without a headline should be named after the event")
+		assert.Equal(t, "Extreme", alert.Severity)
+		assert.Equal(t, time.Date(2025, 9, 1, 16, 0, 0, 0, time.UTC).Unix(), alert.EffectiveEpoch)
+		assert.Equal(t, time.Date(2025, 9, 2, 16, 0, 0, 0, time.UTC).Unix(), alert.ExpiresEpoch)
+		assert.Equal(t, "Hurricane conditions are expected.", alert.Description)
+		assert.Equal(t, "Evacuate.", alert.Instruction)
+	}
+}
+
+func TestWeatherProvider_GetWeather_Marine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"location": {"name": "Plymouth", "tz_id": "Europe/London"}, "current": {"temp_c": 9}}`))
+	}))
+	defer server.Close()
+
+	marineServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("tides") != "yes" {
+			t.Errorf("Expected query parameter 'tides' to be 'yes', got: %s", r.URL.Query().Get("tides"))
+		}
+		if r.URL.Query().Get("q") != "Plymouth Sound" {
+			t.Errorf("Expected query parameter 'q' to be 'Plymouth Sound', got: %s", r.URL.Query().Get("q"))
+		}
+		w.Write([]byte(`{"location": {"name": "Plymouth", "tz_id": "Europe/London"}, "forecast": {"forecastday": [{
+			"date": "2025-07-05",
+			"day": {"tides": [{"tide": [
+				{"tide_time": "2025-07-05 04:12", "tide_height_mt": "4.87", "tide_type": "HIGH"},
+				{"tide_time": "2025-07-05 10:31", "tide_height_mt": "0.92", "tide_type": "LOW"}]}]},
+			"hour": [{"time_epoch": 1751670000, "sig_ht_mt": 1.1, "swell_ht_mt": 0.8, "swell_dir": 245.5,
+				"swell_dir_16_point": "WSW", "swell_period_secs": 9.4, "water_temp_c": 15.2}]}]}}`))
+	}))
+	defer marineServer.Close()
+
+	originalURL, originalMarineURL := weatherAPIURL, weatherAPIMarineURL
+	weatherAPIURL, weatherAPIMarineURL = server.URL, marineServer.URL
+	defer func() { weatherAPIURL, weatherAPIMarineURL = originalURL, originalMarineURL }()
+
+	provider := &weatherapiProvider{cache: newMemoryCache()}
+	weather, err := provider.GetWeather(&Config{Location: "Plymouth", APIKey: "test_api_key"})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+	assert.Nil(t, weather.Marine, "The marine forecast should only be fetched when requested")
+
+	weather, err = provider.GetWeather(&Config{Location: "Plymouth Sound", APIKey: "test_api_key", Marine: true})
+	if err != nil {
+		t.Fatalf("GetWeather returned an error: %v", err)
+	}
+	if assert.NotNil(t, weather.Marine) {
+		assert.Equal(t, []MarineHour{{TimeEpoch: 1751670000, WaveHeightM: 1.1, SwellHeightM: 0.8, SwellDegree: 245,
+			SwellDir: "WSW", SwellPeriodS: 9.4, WaterTempC: 15.2}}, weather.Marine.Hourly)
+		// The tide times are local to the location, an hour ahead of UTC in the summer
+		assert.Equal(t, []WeatherTide{
+			{TimeEpoch: time.Date(2025, 7, 5, 3, 12, 0, 0, time.UTC).Unix(), High: true, HeightM: 4.87},
+			{TimeEpoch: time.Date(2025, 7, 5, 9, 31, 0, 0, time.UTC).Unix(), HeightM: 0.92},
+		}, weather.Marine.Tides)
+	}
+}
+
+func TestWeatherProvider_GetHistory(t *testing.T) {
+	responseJSON, err := os.ReadFile(filepath.Join("samples", "response.json"))
+	if err != nil {
+		t.Fatalf("Failed to read response.json: %v", err)
+	}
+
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Query().Get("dt") == "" {
+			t.Errorf("Expected query parameter 'dt' to be set")
+		}
+		if r.URL.Query().Get("q") != "Saint-Gilles, Brussels" {
+			t.Errorf("Expected query parameter 'q' to be 'Saint-Gilles, Brussels', got: %s", r.URL.Query().Get("q"))
+		}
+		w.Write(responseJSON)
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPIHistoryURL
+	weatherAPIHistoryURL = server.URL
+	defer func() { weatherAPIHistoryURL = originalURL }()
+
+	provider := &weatherapiProvider{cache: newMemoryCache()}
+	config := &Config{Location: "Saint-Gilles, Brussels", APIKey: "test_api_key"}
+	mockNowFunc := func() time.Time {
+		return time.Date(2025, 1, 14, 9, 0, 0, 0, time.UTC)
+	}
+	weather, err := provider.GetHistory(config, "2025-01-12", mockNowFunc)
+	if err != nil {
+		t.Fatalf("GetHistory returned an error: %v", err)
+	}
+
+	// The current conditions summarize the day
+	assert.Equal(t, "Brussels", weather.Current.Location)
+	assert.Equal(t, -0.4, weather.Current.TempC)
+	assert.Equal(t, 80, weather.Current.Humidity)
+	assert.Equal(t, int64(1736640000), weather.Current.LastUpdatedEpoch)
+	assert.Len(t, weather.HourlyForecast, 24)
+
+	// Past days are cached permanently and served without the API
+	failing = true
+	cached, err := provider.GetHistory(config, "2025-01-12", mockNowFunc)
+	assert.NoError(t, err)
+	assert.Equal(t, weather, cached)
+
+	entry, found := provider.cache.Get(cacheKey("weatherapi-history", config.Location) + "|dt=2025-01-12")
+	assert.True(t, found)
+	assert.True(t, entry.Permanent)
+
+	// Days that may still be going on somewhere are not
+	failing = false
+	_, err = provider.GetHistory(config, "2025-01-13", mockNowFunc)
+	assert.NoError(t, err)
+	entry, found = provider.cache.Get(cacheKey("weatherapi-history", config.Location) + "|dt=2025-01-13")
+	assert.True(t, found)
+	assert.False(t, entry.Permanent)
+
+	_, err = provider.GetHistory(config, "yesterday", mockNowFunc)
+	assert.EqualError(t, err, `invalid date "yesterday", expected YYYY-MM-DD`)
+}
+
+func TestWeatherProvider_SearchLocations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "Springfield IL" {
+			t.Errorf("Expected query parameter 'q' to be 'Springfield IL', got: %s", r.URL.Query().Get("q"))
+		}
+		w.Write([]byte(`[{"id": 2629833, "name": "Springfield", "region": "Illinois", "country": "United States of America",
+			"lat": 39.8, "lon": -89.64, "url": "springfield-illinois-united-states-of-america"}]`))
+	}))
+	defer server.Close()
+
+	originalURL := weatherAPISearchURL
+	weatherAPISearchURL = server.URL
+	defer func() { weatherAPISearchURL = originalURL }()
+
+	provider := &weatherapiProvider{}
+	candidates, err := provider.SearchLocations(&Config{APIKey: "test_api_key"}, "Springfield IL")
+	if err != nil {
+		t.Fatalf("SearchLocations returned an error: %v", err)
+	}
+	assert.Equal(t, []LocationCandidate{{ID: 2629833, Name: "Springfield", Region: "Illinois",
+		Country: "United States of America", Lat: 39.8, Lon: -89.64}}, candidates)
 }
 
 func TestGetEmojiForWeatherCode(t *testing.T) {
@@ -94,4 +322,33 @@ func TestGetEmojiForWeatherCode(t *testing.T) {
 	if getEmojiForWeatherCode(9999) != "❓" {
 		t.Errorf("Expected emoji for 9999 to be '❓', got: %s", getEmojiForWeatherCode(9999))
 	}
-}
\ No newline at end of file
+}
+
+func TestWeatherAPIResponse_ToWeather(t *testing.T) {
+	weather := loadMockResponse(t).toWeather()
+
+	assert.Equal(t, "Brussels", weather.Location.Name)
+	assert.Equal(t, "Brussels", weather.Current.Location)
+	assert.Equal(t, "Belgium", weather.Current.Country)
+	assert.Equal(t, 1.3, weather.Current.TempC)
+	assert.Equal(t, "Partly cloudy", weather.Current.Condition)
+	assert.False(t, weather.Current.IsDay)
+	assert.Equal(t, 18.8, weather.Current.GustKph)
+	assert.Equal(t, -4.3, weather.Current.DewpointC)
+	assert.Equal(t, -4.2, weather.Current.WindchillC)
+	assert.Equal(t, 10.0, weather.Current.VisKm)
+	assert.Len(t, weather.DailyForecast, 1)
+	assert.Len(t, weather.HourlyForecast, 24)
+	assert.Equal(t, 297, weather.HourlyForecast[0].WindDegree)
+	assert.Equal(t, 1033.0, weather.HourlyForecast[0].PressureMb)
+	assert.Equal(t, 72, weather.HourlyForecast[0].Cloud)
+	assert.False(t, weather.HourlyForecast[0].WillItRain)
+	assert.Equal(t, time.Date(2025, 1, 12, 7, 28, 0, 0, time.UTC).Unix(), weather.DailyForecast[0].Astro.SunriseEpoch, "Sunrise is at 08:28 AM in Brussels")
+	assert.Equal(t, "weatherapi", weather.Source.Provider)
+}
+
+func TestParseAstroTime(t *testing.T) {
+	zone, _ := time.LoadLocation("Asia/Tokyo")
+	assert.Equal(t, time.Date(2025, 1, 12, 14, 54, 0, 0, zone).Unix(), parseAstroTime("2025-01-12", "02:54 PM", zone))
+	assert.Equal(t, int64(0), parseAstroTime("2025-01-12", "No moonrise", zone))
+}